@@ -100,4 +100,513 @@ create index if not exists idx_project_invites_project on project_invites(projec
 
 create index if not exists idx_tasks_project_id on tasks(project_id);
 create index if not exists idx_tasks_project_status on tasks(project_id, status);
-create index if not exists idx_tasks_project_sort on tasks(project_id, sort_index, created_at);
\ No newline at end of file
+
+create table if not exists project_transfers (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  from_user_id uuid not null references users(id) on delete cascade,
+  to_user_id uuid not null references users(id) on delete cascade,
+  created_at timestamptz not null default now(),
+
+  -- only one outstanding transfer per project
+  unique (project_id)
+);
+
+create index if not exists idx_project_transfers_to on project_transfers(to_user_id, created_at desc);
+
+-- per-user project preferences live on the membership row; the legacy
+-- projects.is_pinned / projects.sort_index columns are copied to the owner once
+do $$
+begin
+    if not exists (
+        select 1 from information_schema.columns
+        where table_name = 'projects_members' and column_name = 'is_pinned'
+    ) then
+        alter table projects_members
+            add column is_pinned boolean not null default false,
+            add column sort_index int not null default 0,
+            add column is_hidden boolean not null default false;
+
+        update projects_members pm
+        set is_pinned = coalesce(p.is_pinned, false),
+            sort_index = p.sort_index
+        from projects p
+        where p.id = pm.project_id
+            and p.owner_id = pm.user_id;
+
+        -- give every user a dense 0..n-1 order across all their memberships
+        update projects_members pm
+        set sort_index = r.rn - 1
+        from (
+            select id, row_number() over (partition by user_id order by sort_index, created_at) as rn
+            from projects_members
+        ) r
+        where r.id = pm.id;
+    end if;
+end $$;
+
+-- archived projects are read-only; deleted projects sit in the owner's trash
+-- until the purge job removes them for good
+alter table projects add column if not exists archived_at timestamptz null;
+alter table projects add column if not exists deleted_at timestamptz null;
+
+create index if not exists idx_projects_deleted_at on projects(deleted_at) where deleted_at is not null;
+
+-- optimistic concurrency: bumped on every edit, exposed to clients as an ETag
+alter table tasks add column if not exists version int not null default 1;
+alter table projects add column if not exists version int not null default 1;
+
+-- lexicographic ordering keys (see internal/rank) replace sort_index shifting;
+-- existing integer positions are converted once, sort_index is no longer written
+alter table tasks add column if not exists rank text collate "C";
+alter table projects_members add column if not exists rank text collate "C";
+
+update tasks t
+set rank = r.k
+from (
+    select id, lpad(to_hex(row_number() over (partition by project_id, status order by sort_index, created_at)), 8, '0') || 'V' as k
+    from tasks
+) r
+where r.id = t.id and t.rank is null;
+
+update projects_members pm
+set rank = r.k
+from (
+    select id, lpad(to_hex(row_number() over (partition by user_id order by sort_index, created_at)), 8, '0') || 'V' as k
+    from projects_members
+) r
+where r.id = pm.id and pm.rank is null;
+
+alter table tasks alter column rank set not null;
+alter table projects_members alter column rank set not null;
+
+drop index if exists idx_tasks_project_sort;
+create index if not exists idx_tasks_project_rank on tasks(project_id, status, rank);
+drop index if exists idx_projects_members_user;
+create index if not exists idx_projects_members_user_rank on projects_members(user_id, rank);
+
+-- subtasks: one level of nesting inside the same project; deleting a parent
+-- promotes its children back to top-level tasks
+alter table tasks add column if not exists parent_id uuid references tasks(id) on delete set null;
+create index if not exists idx_tasks_parent on tasks(parent_id) where parent_id is not null;
+
+create table if not exists task_checklist_items (
+  id uuid primary key default gen_random_uuid(),
+  task_id uuid not null references tasks(id) on delete cascade,
+  title text not null,
+  is_done boolean not null default false,
+  assignee_id uuid references users(id) on delete set null,
+  rank text collate "C" not null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_task_checklist_items_task_rank on task_checklist_items(task_id, rank);
+
+alter table tasks add column if not exists priority text not null default 'medium'; -- urgent | high | medium | low
+
+create table if not exists project_labels (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  name text not null,
+  color text not null default '#6b7280',
+  created_at timestamptz not null default now()
+);
+
+create unique index if not exists idx_project_labels_name on project_labels(project_id, lower(name));
+
+create table if not exists task_labels (
+  task_id uuid not null references tasks(id) on delete cascade,
+  label_id uuid not null references project_labels(id) on delete cascade,
+  primary key (task_id, label_id)
+);
+
+create index if not exists idx_task_labels_label on task_labels(label_id);
+
+-- tasks can have several assignees; the single assignee_id column is moved
+-- into task_assignees once and dropped
+create table if not exists task_assignees (
+  task_id uuid not null references tasks(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  created_at timestamptz not null default now(),
+  primary key (task_id, user_id)
+);
+
+create index if not exists idx_task_assignees_user on task_assignees(user_id);
+
+do $$
+begin
+    if exists (
+        select 1 from information_schema.columns
+        where table_name = 'tasks' and column_name = 'assignee_id'
+    ) then
+        insert into task_assignees (task_id, user_id)
+        select id, assignee_id from tasks where assignee_id is not null
+        on conflict do nothing;
+
+        alter table tasks drop column assignee_id;
+    end if;
+end $$;
+
+create table if not exists task_watchers (
+  task_id uuid not null references tasks(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  created_at timestamptz not null default now(),
+  primary key (task_id, user_id)
+);
+
+create index if not exists idx_task_watchers_user on task_watchers(user_id);
+
+create table if not exists notifications (
+  id uuid primary key default gen_random_uuid(),
+  user_id uuid not null references users(id) on delete cascade,
+  kind text not null, -- task_assigned | task_updated | task_deleted | tasks_updated | tasks_deleted
+  project_id uuid not null references projects(id) on delete cascade,
+  task_id uuid null references tasks(id) on delete set null,
+  task_title text not null,
+  actor_id uuid null references users(id) on delete set null,
+  read_at timestamptz null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_notifications_user on notifications(user_id, created_at desc, id desc);
+
+-- estimates and time tracking
+alter table tasks add column if not exists estimate_hours numeric(8,2) null;
+alter table tasks add column if not exists story_points int null;
+
+create table if not exists time_entries (
+  id uuid primary key default gen_random_uuid(),
+  task_id uuid not null references tasks(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  started_at timestamptz not null,
+  ended_at timestamptz null, -- null while the timer runs
+  note text not null default '',
+  created_at timestamptz not null default now(),
+
+  check (ended_at is null or ended_at >= started_at)
+);
+
+create index if not exists idx_time_entries_task on time_entries(task_id, started_at desc);
+create index if not exists idx_time_entries_user on time_entries(user_id, started_at desc);
+-- one running timer per user
+create unique index if not exists idx_time_entries_running on time_entries(user_id) where ended_at is null;
+
+-- files attached to projects or tasks; the bytes live in internal/storage
+create table if not exists attachments (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  task_id uuid null references tasks(id) on delete cascade,
+  uploader_id uuid null references users(id) on delete set null,
+  filename text not null,
+  content_type text not null,
+  size_bytes bigint not null,
+  storage_key text not null unique,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_attachments_project on attachments(project_id, created_at desc);
+create index if not exists idx_attachments_task on attachments(task_id) where task_id is not null;
+
+-- stored files of deleted attachment rows, drained by the cleanup job
+create table if not exists storage_deletions (
+  storage_key text primary key,
+  queued_at timestamptz not null default now()
+);
+
+create or replace function queue_attachment_file_deletion() returns trigger as $$
+begin
+    insert into storage_deletions (storage_key) values (old.storage_key)
+    on conflict do nothing;
+    return old;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_attachments_queue_file_deletion on attachments;
+create trigger trg_attachments_queue_file_deletion
+    after delete on attachments
+    for each row execute function queue_attachment_file_deletion();
+
+-- full-text search; names and titles weigh more than descriptions and details
+alter table projects add column if not exists search_tsv tsvector
+    generated always as (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) stored;
+alter table tasks add column if not exists search_tsv tsvector
+    generated always as (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(details, '')), 'B')
+    ) stored;
+
+create index if not exists idx_projects_search on projects using gin(search_tsv);
+create index if not exists idx_tasks_search on tasks using gin(search_tsv);
+
+-- saved task filters; project_id null spans all of the owner's projects
+create table if not exists saved_views (
+  id uuid primary key default gen_random_uuid(),
+  owner_id uuid not null references users(id) on delete cascade,
+  project_id uuid null references projects(id) on delete cascade,
+  shared boolean not null default false,
+  name text not null,
+  filter jsonb not null default '{}',
+  sort text not null default 'board',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+
+  check (not shared or project_id is not null)
+);
+
+create index if not exists idx_saved_views_owner on saved_views(owner_id);
+create index if not exists idx_saved_views_project on saved_views(project_id) where shared;
+
+-- bulk task changes notify once for the whole batch
+alter table notifications add column if not exists task_count int not null default 1;
+
+-- human-readable task keys ("FRG-42"): a short key per project and a
+-- per-project task counter, bumped under the project row's lock
+do $$
+begin
+    if not exists (
+        select 1 from information_schema.columns
+        where table_name = 'projects' and column_name = 'key'
+    ) then
+        alter table projects
+            add column key text,
+            add column task_seq int not null default 0;
+        alter table tasks add column number int;
+
+        update projects
+        set key = case
+            when k ~ '^[A-Z][A-Z0-9]+$' then k
+            else 'PRJ'
+        end
+        from (
+            select id as pid, upper(left(regexp_replace(name, '[^A-Za-z0-9]', '', 'g'), 3)) as k
+            from projects
+        ) d
+        where d.pid = projects.id;
+
+        update tasks t
+        set number = n.rn
+        from (
+            select id, row_number() over (partition by project_id order by created_at, id) as rn
+            from tasks
+        ) n
+        where n.id = t.id;
+
+        update projects p
+        set task_seq = coalesce((select max(number) from tasks where project_id = p.id), 0);
+
+        alter table projects alter column key set not null;
+        alter table tasks alter column number set not null;
+    end if;
+end $$;
+
+create unique index if not exists idx_tasks_project_number on tasks(project_id, number);
+
+-- sprints time-box tasks; committed points are snapshotted when a sprint
+-- starts and the outcome when it closes, so summaries survive later edits
+create table if not exists sprints (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  name text not null,
+  goal text not null default '',
+  start_date date not null,
+  end_date date not null,
+  status text not null default 'planned', -- planned | active | closed
+  started_at timestamptz null,
+  closed_at timestamptz null,
+  committed_tasks int not null default 0,
+  committed_points int not null default 0,
+  completed_tasks int not null default 0,
+  completed_points int not null default 0,
+  carried_tasks int not null default 0,
+  carried_points int not null default 0,
+  created_at timestamptz not null default now(),
+
+  check (end_date >= start_date),
+  check (status in ('planned', 'active', 'closed'))
+);
+
+create index if not exists idx_sprints_project on sprints(project_id, start_date);
+-- one running sprint per project
+create unique index if not exists idx_sprints_active on sprints(project_id) where status = 'active';
+
+create table if not exists milestones (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  name text not null,
+  description text not null default '',
+  due_date date null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_milestones_project on milestones(project_id);
+
+alter table tasks add column if not exists sprint_id uuid null references sprints(id) on delete set null;
+alter table tasks add column if not exists milestone_id uuid null references milestones(id) on delete set null;
+create index if not exists idx_tasks_sprint on tasks(sprint_id) where sprint_id is not null;
+create index if not exists idx_tasks_milestone on tasks(milestone_id) where milestone_id is not null;
+
+-- task state over time for burndown and velocity; one row per change of
+-- status, difficulty or sprint. No foreign keys, so the trigger can record
+-- deletions; the purge job drops rows of purged projects.
+create table if not exists task_history (
+  id bigserial primary key,
+  task_id uuid not null,
+  project_id uuid not null,
+  status text not null,
+  difficulty int not null,
+  sprint_id uuid null,
+  deleted boolean not null default false,
+  recorded_at timestamptz not null default now()
+);
+
+create index if not exists idx_task_history_project on task_history(project_id, recorded_at);
+create index if not exists idx_task_history_task on task_history(task_id, recorded_at desc, id desc);
+
+-- tasks older than the history start from their current state
+insert into task_history (task_id, project_id, status, difficulty, sprint_id, recorded_at)
+select t.id, t.project_id, t.status, t.difficulty, t.sprint_id, t.created_at
+from tasks t
+where not exists (select 1 from task_history h where h.task_id = t.id);
+
+create or replace function record_task_history() returns trigger as $$
+begin
+    if tg_op = 'DELETE' then
+        insert into task_history (task_id, project_id, status, difficulty, sprint_id, deleted)
+        values (old.id, old.project_id, old.status, old.difficulty, old.sprint_id, true);
+        return old;
+    end if;
+
+    if tg_op = 'UPDATE'
+        and new.status is not distinct from old.status
+        and new.difficulty is not distinct from old.difficulty
+        and new.sprint_id is not distinct from old.sprint_id then
+        return new;
+    end if;
+
+    insert into task_history (task_id, project_id, status, difficulty, sprint_id)
+    values (new.id, new.project_id, new.status, new.difficulty, new.sprint_id);
+    return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_tasks_history on tasks;
+create trigger trg_tasks_history
+    after insert or update or delete on tasks
+    for each row execute function record_task_history();
+
+-- staged Trello/CSV imports awaiting confirmation
+create table if not exists task_imports (
+  id uuid primary key default gen_random_uuid(),
+  project_id uuid not null references projects(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  format text not null check (format in ('trello', 'csv')),
+  filename text not null default '',
+  rows jsonb not null,
+  created_at timestamptz not null default now(),
+  confirmed_at timestamptz null
+);
+
+create index if not exists idx_task_imports_created on task_imports(created_at);
+
+-- project templates: an export bundle without members
+create table if not exists project_templates (
+  id uuid primary key default gen_random_uuid(),
+  owner_id uuid not null references users(id) on delete cascade,
+  name text not null,
+  description text not null default '',
+  bundle jsonb not null,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_project_templates_owner on project_templates(owner_id);
+
+-- organizations own projects; their owners and admins manage all of them
+create table if not exists organizations (
+  id uuid primary key default gen_random_uuid(),
+  name text not null,
+  created_by uuid null references users(id) on delete set null,
+  created_at timestamptz not null default now()
+);
+
+create table if not exists org_members (
+  org_id uuid not null references organizations(id) on delete cascade,
+  user_id uuid not null references users(id) on delete cascade,
+  role text not null default 'member' check (role in ('owner', 'admin', 'member')),
+  created_at timestamptz not null default now(),
+  primary key (org_id, user_id)
+);
+
+create index if not exists idx_org_members_user on org_members(user_id);
+
+alter table projects add column if not exists org_id uuid null references organizations(id);
+create index if not exists idx_projects_org on projects(org_id) where org_id is not null;
+
+-- An organization's projects outlive their owner's account: before the user
+-- row goes (and cascades to projects.owner_id), ownership passes to the
+-- longest-standing org owner, then admin, then member, who joins the
+-- project. With nobody left in the org to take it, the delete is refused.
+create or replace function reassign_org_projects() returns trigger as $$
+declare
+    orphan uuid;
+begin
+    with heirs as (
+        select distinct on (om.org_id) om.org_id, om.user_id
+        from org_members om
+        where om.user_id <> old.id
+        order by om.org_id,
+            case om.role when 'owner' then 0 when 'admin' then 1 else 2 end,
+            om.created_at, om.user_id
+    ),
+    moved as (
+        update projects p
+        set owner_id = h.user_id
+        from heirs h
+        where p.owner_id = old.id and p.org_id = h.org_id
+        returning p.id, p.owner_id
+    )
+    insert into projects_members (project_id, user_id, username, role_key, rank)
+    select m.id, m.owner_id, u.username, 'pm',
+        coalesce((select max(pm.rank) from projects_members pm where pm.user_id = m.owner_id), '') || 'V'
+    from moved m
+    join users u on u.id = m.owner_id
+    where not exists (
+        select 1 from projects_members pm
+        where pm.project_id = m.id and pm.user_id = m.owner_id
+    );
+
+    select p.id into orphan
+    from projects p
+    where p.owner_id = old.id and p.org_id is not null
+    limit 1;
+    if orphan is not null then
+        raise exception 'user % is the last member of the organization owning project %', old.id, orphan
+            using errcode = 'restrict_violation';
+    end if;
+
+    return old;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_users_reassign_org_projects on users;
+create trigger trg_users_reassign_org_projects
+    before delete on users
+    for each row execute function reassign_org_projects();
+
+-- per-field profile visibility: public | teammates | private (missing = public)
+alter table profiles add column if not exists visibility jsonb not null default '{}';
+
+-- assignments from an imported project bundle waiting on an invitee: they
+-- apply when the invite is accepted and vanish with it
+create table if not exists invite_assignments (
+  invite_id uuid not null references project_invites(id) on delete cascade,
+  kind text not null check (kind in ('assignee', 'watcher', 'checklist')),
+  task_id uuid not null references tasks(id) on delete cascade,
+  checklist_item_id uuid null references task_checklist_items(id) on delete cascade,
+
+  check ((kind = 'checklist') = (checklist_item_id is not null))
+);
+
+create index if not exists idx_invite_assignments_invite on invite_assignments(invite_id);
@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge-api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// UploadLimits bounds what can be attached to tasks and projects.
+type UploadLimits struct {
+	MaxBytes     int64
+	AllowedTypes []string // exact MIME types, or "image/*" style prefixes
+}
+
+var DefaultUploadLimits = UploadLimits{
+	MaxBytes: 25 << 20,
+	AllowedTypes: []string{
+		"image/*",
+		"application/pdf",
+		"text/plain",
+		"text/markdown",
+		"text/csv",
+		"application/json",
+		"application/zip",
+		"application/msword",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.ms-excel",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.ms-powerpoint",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	},
+}
+
+// ========= Attachment DTOs (responses) =========
+type Attachment struct {
+	ID               string  `json:"id"`
+	ProjectID        string  `json:"project_id"`
+	TaskID           *string `json:"task_id"`
+	Filename         string  `json:"filename"`
+	ContentType      string  `json:"content_type"`
+	SizeBytes        int64   `json:"size_bytes"`
+	UploaderID       *string `json:"uploader_id"`
+	UploaderUsername *string `json:"uploader_username"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+// ListProjectAttachments lists every attachment in a project, including the
+// ones on its tasks.
+func (h *Handler) ListProjectAttachments(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	h.writeAttachments(c, ctx, `a.project_id::text = $1`, projectID)
+}
+
+func (h *Handler) ListTaskAttachments(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, false) {
+		return
+	}
+
+	h.writeAttachments(c, ctx, `a.task_id::text = $1`, taskID)
+}
+
+func (h *Handler) UploadProjectAttachment(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 2*time.Minute)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	h.upload(c, ctx, uid, projectID, "")
+}
+
+func (h *Handler) UploadTaskAttachment(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 2*time.Minute)
+	defer cancel()
+
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, true) {
+		return
+	}
+
+	h.upload(c, ctx, uid, projectID, taskID)
+}
+
+// DownloadAttachment streams the file back with its original name.
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, attachmentID, ok := parseAttachmentPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 2*time.Minute)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	var filename, contentType, key string
+	var size int64
+	if err := h.DB.QueryRow(ctx, `
+		select filename, content_type, size_bytes, storage_key
+		from attachments
+		where project_id::text = $1 and id::text = $2
+	`, projectID, attachmentID).Scan(&filename, &contentType, &size, &key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	body, err := h.Storage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer body.Close()
+
+	// never let an uploaded file render inline as a page on our origin
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.DataFromReader(http.StatusOK, size, contentType, body, nil)
+}
+
+// DeleteAttachment is allowed for the uploader and whoever manages the
+// project.
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, attachmentID, ok := parseAttachmentPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	// The row goes first; a trigger queues the stored file for the cleanup
+	// job, so a storage outage never leaves a dangling row behind.
+	var deleted bool
+	if err := h.DB.QueryRow(ctx, `
+		with target as (
+			select a.id, (a.uploader_id::text = $3 or `+managedBy("p", "$3")+`) as may_delete
+			from attachments a
+			join projects p on p.id = a.project_id
+			where a.project_id::text = $1 and a.id::text = $2
+		),
+		del as (
+			delete from attachments
+			where id in (select id from target where may_delete)
+			returning id
+		)
+		select exists (select 1 from del)
+		from target
+	`, projectID, attachmentID, uid).Scan(&deleted); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the uploader or project owner can delete this"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// upload reads the multipart "file" field, checks it against h.Uploads,
+// stores it and records it on the project (and task, if taskID is set).
+func (h *Handler) upload(c *gin.Context, ctx context.Context, uid, projectID, taskID string) {
+	// leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Uploads.MaxBytes+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": h.Uploads.MaxBytes})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	if header.Size > h.Uploads.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": h.Uploads.MaxBytes})
+		return
+	}
+	if header.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "empty file"})
+		return
+	}
+
+	filename := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if filename == "" || filename == "." || filename == "/" {
+		filename = "file"
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unreadable file"})
+		return
+	}
+	head = head[:n]
+
+	contentType := detectContentType(head, filename)
+	if !h.Uploads.allows(contentType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "file type not allowed", "content_type": contentType})
+		return
+	}
+
+	attachmentID := uuid.NewString()
+	key := fmt.Sprintf("projects/%s/%s", projectID, attachmentID)
+	if err := h.Storage.Put(ctx, key, io.MultiReader(bytes.NewReader(head), f), header.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	var task any = nil
+	if taskID != "" {
+		task = taskID
+	}
+	if _, err := h.DB.Exec(ctx, `
+		insert into attachments (id, project_id, task_id, uploader_id, filename, content_type, size_bytes, storage_key)
+		values ($1::uuid, $2::uuid, $3::uuid, $4::uuid, $5, $6, $7, $8)
+	`, attachmentID, projectID, task, uid, filename, contentType, header.Size, key); err != nil {
+		if derr := h.Storage.Delete(context.Background(), key); derr != nil {
+			log.Printf("attachment %s: cleanup after failed insert: %v", key, derr)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadAttachment(ctx, h.DB, attachmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) writeAttachments(c *gin.Context, ctx context.Context, where string, arg string) {
+	rows, err := h.DB.Query(ctx, `
+		select `+attachmentColumns+`
+		from attachments a
+		left join users u on u.id = a.uploader_id
+		where `+where+`
+		order by a.created_at desc, a.id desc
+	`, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// detectContentType trusts the file's bytes over its name. Containers and
+// plain text sniff too generically (a .docx is a zip, a .md is text), so for
+// those the extension may narrow the type.
+func detectContentType(head []byte, filename string) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	byExt, _, _ := mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))))
+	if byExt == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".md", ".markdown":
+			byExt = "text/markdown"
+		}
+	}
+
+	switch sniffed {
+	case "application/octet-stream":
+		if byExt != "" && !strings.HasPrefix(byExt, "text/") {
+			return byExt
+		}
+	case "application/zip":
+		if strings.HasPrefix(byExt, "application/vnd.openxmlformats-officedocument.") {
+			return byExt
+		}
+	case "text/plain":
+		if byExt == "text/markdown" || byExt == "text/csv" || byExt == "application/json" {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+func (l UploadLimits) allows(contentType string) bool {
+	for _, t := range l.AllowedTypes {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(contentType, prefix) {
+				return true
+			}
+		} else if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAttachmentPath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	attachmentID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("attachmentId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return "", "", false
+	}
+	return projectID.String(), attachmentID.String(), true
+}
+
+// attachmentColumns lists the columns scanAttachment expects. Queries must
+// alias attachments as a and left join the uploader as u.
+const attachmentColumns = `
+	a.id::text,
+	a.project_id::text,
+	coalesce(a.task_id::text, ''),
+	a.filename,
+	a.content_type,
+	a.size_bytes,
+	coalesce(a.uploader_id::text, ''),
+	coalesce(u.username, ''),
+	a.created_at`
+
+func loadAttachment(ctx context.Context, q querier, attachmentID string) (Attachment, error) {
+	return scanAttachment(q.QueryRow(ctx, `
+		select `+attachmentColumns+`
+		from attachments a
+		left join users u on u.id = a.uploader_id
+		where a.id::text = $1
+	`, attachmentID))
+}
+
+func scanAttachment(row pgx.Row) (Attachment, error) {
+	var a Attachment
+	var taskID, uploaderID, uploaderUsername string
+	var createdAt time.Time
+	if err := row.Scan(
+		&a.ID,
+		&a.ProjectID,
+		&taskID,
+		&a.Filename,
+		&a.ContentType,
+		&a.SizeBytes,
+		&uploaderID,
+		&uploaderUsername,
+		&createdAt,
+	); err != nil {
+		return Attachment{}, err
+	}
+
+	if taskID != "" {
+		a.TaskID = &taskID
+	}
+	if uploaderID != "" {
+		a.UploaderID = &uploaderID
+		a.UploaderUsername = &uploaderUsername
+	}
+	a.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	return a, nil
+}
+
+// ParseAllowedTypes reads a comma-separated MIME list such as
+// "image/*,application/pdf"; an empty string keeps the defaults.
+func ParseAllowedTypes(raw string) []string {
+	var out []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return DefaultUploadLimits.AllowedTypes
+	}
+	return out
+}
+
+// ParseMaxBytes reads a byte count; empty or invalid keeps the default.
+func ParseMaxBytes(raw string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || n <= 0 {
+		return DefaultUploadLimits.MaxBytes
+	}
+	return n
+}
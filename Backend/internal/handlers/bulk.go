@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"forge-api/internal/rank"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxBulkTasks caps one bulk request so its transaction stays short.
+const maxBulkTasks = 200
+
+// ========= Bulk DTOs (responses) =========
+type BulkTaskResult struct {
+	Tasks   []Task   `json:"tasks"`   // updated tasks in board order
+	Deleted []string `json:"deleted"` // ids of deleted tasks
+}
+
+// ========= Requests =========
+// bulkTaskReq either deletes the tasks or applies every given change to all
+// of them.
+type bulkTaskReq struct {
+	TaskIDs     []string  `json:"task_ids"`
+	Delete      bool      `json:"delete"`
+	Status      *string   `json:"status"`
+	AssigneeIDs *[]string `json:"assignee_ids"`
+	Difficulty  *int      `json:"difficulty"`
+}
+
+// BulkUpdateTasks moves, reassigns, re-grades or deletes a set of tasks in
+// one transaction. Tasks moved to another column are appended to it in
+// their current board order. Followers get one notification for the whole
+// batch rather than one per task.
+func (h *Handler) BulkUpdateTasks(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req bulkTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	taskIDs, ok := normalizeTaskIDs(req.TaskIDs)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+	if len(taskIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing task_ids"})
+		return
+	}
+	if len(taskIDs) > maxBulkTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many tasks"})
+		return
+	}
+
+	changes := req.Status != nil || req.AssigneeIDs != nil || req.Difficulty != nil
+	if req.Delete && changes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delete can't be combined with other changes"})
+		return
+	}
+	if !req.Delete && !changes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to change"})
+		return
+	}
+
+	if req.Status != nil {
+		s := strings.TrimSpace(*req.Status)
+		if !isValidTaskStatus(s) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		req.Status = &s
+	}
+	if req.Difficulty != nil && (*req.Difficulty < 1 || *req.Difficulty > 5) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid difficulty"})
+		return
+	}
+
+	// assignee_ids replaces the whole set on every task; [] unassigns everyone
+	var assigneeIDs []string
+	if req.AssigneeIDs != nil {
+		ids, ok := normalizeUserIDs(*req.AssigneeIDs)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee"})
+			return
+		}
+		assigneeIDs = ids
+	}
+
+	ctx, cancel := contextTimeout(c, 15*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	allowed, err := isProjectMember(ctx, tx, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, tx, projectID) {
+		return
+	}
+	if !requireAssignableMembers(c, ctx, tx, projectID, assigneeIDs) {
+		return
+	}
+
+	// Lock the tasks in board order, which is also the order moved tasks
+	// keep in their new column
+	rows, err := tx.Query(ctx, `
+		select t.id::text, t.status
+		from tasks t
+		where t.project_id::text = $1 and t.id::text = any($2)
+		order by `+taskStatusRank+`, t.rank, t.id
+		for update
+	`, projectID, taskIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	type lockedTask struct {
+		ID     string
+		Status string
+	}
+	locked, err := pgx.CollectRows(rows, pgx.RowToStructByPos[lockedTask])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if len(locked) != len(taskIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	ordered := make([]string, len(locked))
+	for i, t := range locked {
+		ordered[i] = t.ID
+	}
+
+	if req.Delete {
+		// tell followers while the tasks (and their followers) still exist
+		if err := notifyBulkFollowers(ctx, tx, projectID, ordered, uid, NotificationTasksDeleted); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if _, err := tx.Exec(ctx, `
+			delete from tasks
+			where project_id::text = $1 and id::text = any($2)
+		`, projectID, ordered); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		c.JSON(http.StatusOK, BulkTaskResult{Tasks: []Task{}, Deleted: ordered})
+		return
+	}
+
+	// Tasks changing column go after the column's current last task; the
+	// ones already there keep their place
+	ranks := make([]*string, len(locked))
+	if req.Status != nil {
+		var moving []int
+		for i, t := range locked {
+			if t.Status != *req.Status {
+				moving = append(moving, i)
+			}
+		}
+		if len(moving) > 0 {
+			var last string
+			if err := tx.QueryRow(ctx, `
+				select coalesce(max(rank), '')
+				from tasks
+				where project_id::text = $1 and status = $2 and id::text <> all($3)
+			`, projectID, *req.Status, ordered).Scan(&last); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+			for j, r := range rank.Append(last, len(moving)) {
+				ranks[moving[j]] = &r
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update tasks t
+		set status = coalesce($3, t.status),
+			difficulty = coalesce($4, t.difficulty),
+			rank = coalesce(m.rank, t.rank),
+			version = t.version + 1
+		from unnest($2::text[], $5::text[]) as m(id, rank)
+		where t.project_id::text = $1 and t.id::text = m.id
+	`, projectID, ordered, req.Status, req.Difficulty, ranks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if req.AssigneeIDs != nil {
+		for _, id := range ordered {
+			if _, err := setTaskAssignees(ctx, tx, id, assigneeIDs); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+		}
+	}
+
+	// Followers now include any new assignees
+	if err := notifyBulkFollowers(ctx, tx, projectID, ordered, uid, NotificationTasksUpdated); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	rows, err = tx.Query(ctx, `
+		select `+taskColumns+`
+		from tasks t
+		where t.project_id::text = $1 and t.id::text = any($2)
+		order by `+taskStatusRank+`, t.rank, t.id
+	`, projectID, ordered)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := BulkTaskResult{Tasks: []Task{}, Deleted: []string{}}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.Tasks = append(out.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	rows.Close()
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func normalizeTaskIDs(raw []string) ([]string, bool) {
+	seen := make(map[string]bool, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, id := range raw {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, true
+}
+
+// notifyBulkFollowers sends each follower of any of taskIDs a single
+// notification covering the whole batch, except the actor.
+func notifyBulkFollowers(ctx context.Context, tx pgx.Tx, projectID string, taskIDs []string, actorID, kind string) error {
+	_, err := tx.Exec(ctx, `
+		insert into notifications (user_id, kind, project_id, task_title, task_count, actor_id)
+		select r.user_id, $3, $1::uuid, '', $4, $5::uuid
+		from (
+			select user_id from task_assignees where task_id::text = any($2)
+			union
+			select user_id from task_watchers where task_id::text = any($2)
+		) as r
+		where r.user_id <> $5::uuid
+	`, projectID, taskIDs, kind, len(taskIDs), actorID)
+	return err
+}
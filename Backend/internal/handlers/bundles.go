@@ -0,0 +1,727 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"forge-api/internal/rank"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Project bundles are the portable export format. Bump projectBundleVersion
+// when the shape changes; imports accept any version up to it.
+const (
+	projectBundleFormat  = "forge.project"
+	projectBundleVersion = 1
+
+	maxBundleBytes = 20 << 20
+	maxBundleTasks = 10000
+)
+
+// Kinds of invite_assignments: what an imported task holds for an invitee
+// until they accept.
+const (
+	pendingAssignee  = "assignee"
+	pendingWatcher   = "watcher"
+	pendingChecklist = "checklist"
+)
+
+// ========= Bundle DTOs (requests and responses) =========
+// ProjectBundle refers to people by username and to tasks by their number
+// (Ref), so it carries over between instances.
+type ProjectBundle struct {
+	Format     string         `json:"format"`
+	Version    int            `json:"version"`
+	ExportedAt string         `json:"exported_at"`
+	Project    BundleProject  `json:"project"`
+	Members    []BundleMember `json:"members"`
+	Labels     []BundleLabel  `json:"labels"`
+	Tasks      []BundleTask   `json:"tasks"`
+}
+
+type BundleProject struct {
+	Name        string   `json:"name"`
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Owner       string   `json:"owner"` // username
+	CustomRoles []string `json:"custom_roles"`
+}
+
+type BundleMember struct {
+	Username string `json:"username"`
+	RoleKey  string `json:"role_key"`
+}
+
+type BundleLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// BundleTask keeps its board order as Position within its status column.
+type BundleTask struct {
+	Ref           int                   `json:"ref"`
+	Title         string                `json:"title"`
+	Details       string                `json:"details"`
+	Status        string                `json:"status"`
+	Position      int                   `json:"position"`
+	Difficulty    int                   `json:"difficulty"`
+	Priority      string                `json:"priority"`
+	ParentRef     *int                  `json:"parent_ref"`
+	Assignees     []string              `json:"assignees"`
+	Watchers      []string              `json:"watchers"`
+	Labels        []string              `json:"labels"` // label names
+	EstimateHours *float64              `json:"estimate_hours"`
+	StoryPoints   *int                  `json:"story_points"`
+	Checklist     []BundleChecklistItem `json:"checklist"`
+}
+
+type BundleChecklistItem struct {
+	Title    string  `json:"title"`
+	IsDone   bool    `json:"is_done"`
+	Assignee *string `json:"assignee"`
+}
+
+// ImportResult reports what became of the people in a bundle. Only the
+// importer joins right away: bundle members are invited, and their
+// assignments apply once they accept. NotMemberUsers exist here but were
+// not bundle members, MissingUsers matched no user; assignments of both
+// were skipped.
+type ImportResult struct {
+	Project        Project  `json:"project"`
+	InvitedUsers   []string `json:"invited_users"`
+	NotMemberUsers []string `json:"not_member_users"`
+	MissingUsers   []string `json:"missing_users"`
+}
+
+// ExportProject returns the project as a ProjectBundle, as a download.
+func (h *Handler) ExportProject(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 15*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	tx, err := h.DB.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	out, err := exportProjectBundle(ctx, tx, projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ToLower(out.Project.Key)+"-export.json"))
+	c.JSON(http.StatusOK, out)
+}
+
+// ImportProject recreates a bundle as a new project owned by the caller.
+// Members are matched to local users by username and invited; unknown ones
+// are reported, not created.
+func (h *Handler) ImportProject(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	usrAny, _ := c.Get("usr")
+	usr, ok := usrAny.(string)
+	if !ok || usr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "bad auth"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBundleBytes)
+
+	var bundle ProjectBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	if err := bundle.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 30*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	projectID, result, err := importProjectBundle(ctx, tx, uid, usr, &bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	result.Project, err = loadProject(ctx, tx, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// validate checks a bundle can be imported and fills in defaults. Errors
+// are fit for a 400 response.
+func (b *ProjectBundle) validate() error {
+	if b.Format != projectBundleFormat {
+		return errors.New("not a project bundle")
+	}
+	if b.Version < 1 || b.Version > projectBundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+
+	b.Project.Name = strings.TrimSpace(b.Project.Name)
+	if b.Project.Name == "" {
+		return errors.New("missing project name")
+	}
+	if key, ok := normalizeProjectKey(b.Project.Key); ok {
+		b.Project.Key = key
+	} else {
+		b.Project.Key = deriveProjectKey(b.Project.Name)
+	}
+
+	if len(b.Tasks) > maxBundleTasks {
+		return errors.New("too many tasks")
+	}
+
+	refs := make(map[int]*BundleTask, len(b.Tasks))
+	for i := range b.Tasks {
+		t := &b.Tasks[i]
+		if t.Ref < 1 {
+			return fmt.Errorf("task %d: invalid ref", i)
+		}
+		if refs[t.Ref] != nil {
+			return fmt.Errorf("task %d: duplicate ref", t.Ref)
+		}
+		refs[t.Ref] = t
+
+		t.Title = strings.TrimSpace(t.Title)
+		if t.Title == "" {
+			return fmt.Errorf("task %d: missing title", t.Ref)
+		}
+		if t.Status == "" {
+			t.Status = "backlog"
+		}
+		if !isValidTaskStatus(t.Status) {
+			return fmt.Errorf("task %d: invalid status", t.Ref)
+		}
+		if t.Difficulty == 0 {
+			t.Difficulty = 2
+		}
+		if t.Difficulty < 1 || t.Difficulty > 5 {
+			return fmt.Errorf("task %d: invalid difficulty", t.Ref)
+		}
+		if t.Priority == "" {
+			t.Priority = "medium"
+		}
+		if !isValidTaskPriority(t.Priority) {
+			return fmt.Errorf("task %d: invalid priority", t.Ref)
+		}
+		if t.EstimateHours != nil && (*t.EstimateHours < 0 || *t.EstimateHours > 10000) {
+			return fmt.Errorf("task %d: invalid estimate_hours", t.Ref)
+		}
+		if t.StoryPoints != nil && (*t.StoryPoints < 0 || *t.StoryPoints > 1000) {
+			return fmt.Errorf("task %d: invalid story_points", t.Ref)
+		}
+		for _, item := range t.Checklist {
+			if strings.TrimSpace(item.Title) == "" {
+				return fmt.Errorf("task %d: checklist item without title", t.Ref)
+			}
+		}
+	}
+
+	// subtasks are one level deep, as in the app
+	for _, t := range b.Tasks {
+		if t.ParentRef == nil {
+			continue
+		}
+		parent := refs[*t.ParentRef]
+		if parent == nil || parent.Ref == t.Ref {
+			return fmt.Errorf("task %d: unknown parent_ref", t.Ref)
+		}
+		if parent.ParentRef != nil {
+			return fmt.Errorf("task %d: subtasks cannot be nested", t.Ref)
+		}
+	}
+
+	for i := range b.Labels {
+		b.Labels[i].Name = strings.TrimSpace(b.Labels[i].Name)
+		if b.Labels[i].Name == "" {
+			return errors.New("label without name")
+		}
+		col := strings.ToLower(strings.TrimSpace(b.Labels[i].Color))
+		if !labelColorRe.MatchString(col) {
+			col = defaultLabelColor
+		}
+		b.Labels[i].Color = col
+	}
+	return nil
+}
+
+// exportProjectBundle reads a project into a bundle. It returns
+// pgx.ErrNoRows when the project is missing or in the trash.
+func exportProjectBundle(ctx context.Context, q querier, projectID string) (ProjectBundle, error) {
+	out := ProjectBundle{
+		Format:     projectBundleFormat,
+		Version:    projectBundleVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Members:    []BundleMember{},
+		Labels:     []BundleLabel{},
+		Tasks:      []BundleTask{},
+	}
+
+	if err := q.QueryRow(ctx, `
+		select p.name, p.key, p.description, u.username, p.custom_roles
+		from projects p
+		join users u on u.id = p.owner_id
+		where p.id::text = $1 and p.deleted_at is null
+	`, projectID).Scan(
+		&out.Project.Name,
+		&out.Project.Key,
+		&out.Project.Description,
+		&out.Project.Owner,
+		&out.Project.CustomRoles,
+	); err != nil {
+		return ProjectBundle{}, err
+	}
+	if out.Project.CustomRoles == nil {
+		out.Project.CustomRoles = []string{}
+	}
+
+	rows, err := q.Query(ctx, `
+		select u.username, pm.role_key
+		from projects_members pm
+		join users u on u.id = pm.user_id
+		where pm.project_id::text = $1
+		order by lower(u.username)
+	`, projectID)
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+	out.Members, err = pgx.CollectRows(rows, pgx.RowToStructByPos[BundleMember])
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+
+	rows, err = q.Query(ctx, `
+		select name, color
+		from project_labels
+		where project_id::text = $1
+		order by lower(name)
+	`, projectID)
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+	out.Labels, err = pgx.CollectRows(rows, pgx.RowToStructByPos[BundleLabel])
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+
+	rows, err = q.Query(ctx, `
+		select
+			t.number,
+			t.title,
+			t.details,
+			t.status,
+			(row_number() over (partition by t.status order by t.rank, t.id) - 1)::int,
+			t.difficulty,
+			t.priority,
+			p.number,
+			array(
+				select u.username from task_assignees ta join users u on u.id = ta.user_id
+				where ta.task_id = t.id order by lower(u.username)
+			),
+			array(
+				select u.username from task_watchers tw join users u on u.id = tw.user_id
+				where tw.task_id = t.id order by lower(u.username)
+			),
+			array(
+				select l.name from task_labels tl join project_labels l on l.id = tl.label_id
+				where tl.task_id = t.id order by lower(l.name)
+			),
+			t.estimate_hours::float8,
+			t.story_points,
+			coalesce((
+				select json_agg(json_build_object(
+					'title', ci.title,
+					'is_done', ci.is_done,
+					'assignee', cu.username
+				) order by ci.rank, ci.id)
+				from task_checklist_items ci
+				left join users cu on cu.id = ci.assignee_id
+				where ci.task_id = t.id
+			), '[]'::json)
+		from tasks t
+		left join tasks p on p.id = t.parent_id
+		where t.project_id::text = $1
+		order by `+taskStatusRank+`, t.rank, t.id
+	`, projectID)
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+	out.Tasks, err = pgx.CollectRows(rows, pgx.RowToStructByPos[BundleTask])
+	if err != nil {
+		return ProjectBundle{}, err
+	}
+	return out, nil
+}
+
+// importProjectBundle creates a validated bundle as a new project owned by
+// ownerID. It returns the new id and an ImportResult with every field but
+// Project filled in.
+// Task numbers keep the bundle's refs, so keys like "FRG-42" survive.
+func importProjectBundle(ctx context.Context, tx pgx.Tx, ownerID, ownerUsername string, b *ProjectBundle) (string, ImportResult, error) {
+	// custom roles, minus built-ins and duplicates
+	customRoles := []string{}
+	for _, r := range b.Project.CustomRoles {
+		r = strings.TrimSpace(r)
+		if _, dup := findRole(customRoles, r); r == "" || dup || isBuiltinRole(r) {
+			continue
+		}
+		customRoles = append(customRoles, r)
+	}
+	sortRoles(customRoles)
+	validRole := func(role string) string {
+		if isBuiltinRole(role) {
+			return strings.ToLower(role)
+		}
+		if r, ok := findRole(customRoles, role); ok {
+			return r
+		}
+		return builtinRoleKeys[0]
+	}
+
+	var projectID string
+	if err := tx.QueryRow(ctx, `
+		insert into projects (name, key, description, owner_id, custom_roles)
+		values ($1, $2, $3, $4, $5)
+		returning id::text
+	`, b.Project.Name, b.Project.Key, strings.TrimSpace(b.Project.Description), ownerID, customRoles).Scan(&projectID); err != nil {
+		return "", ImportResult{}, err
+	}
+
+	// Resolve every username the bundle mentions in one query
+	mentioned := map[string]bool{}
+	for _, m := range b.Members {
+		mentioned[strings.ToLower(strings.TrimSpace(m.Username))] = true
+	}
+	for _, t := range b.Tasks {
+		for _, u := range append(append([]string{}, t.Assignees...), t.Watchers...) {
+			mentioned[strings.ToLower(strings.TrimSpace(u))] = true
+		}
+		for _, item := range t.Checklist {
+			if item.Assignee != nil {
+				mentioned[strings.ToLower(strings.TrimSpace(*item.Assignee))] = true
+			}
+		}
+	}
+	delete(mentioned, "")
+	names := make([]string, 0, len(mentioned))
+	for n := range mentioned {
+		names = append(names, n)
+	}
+
+	rows, err := tx.Query(ctx, `
+		select lower(username), id::text, username
+		from users
+		where lower(username) = any($1)
+	`, names)
+	if err != nil {
+		return "", ImportResult{}, err
+	}
+	type localUser struct{ ID, Username string }
+	users := map[string]localUser{}
+	for rows.Next() {
+		var key string
+		var u localUser
+		if err := rows.Scan(&key, &u.ID, &u.Username); err != nil {
+			rows.Close()
+			return "", ImportResult{}, err
+		}
+		users[key] = u
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", ImportResult{}, err
+	}
+
+	// The importer owns the project, keeping their bundle role if they had one
+	ownerRole := builtinRoleKeys[0]
+	for _, m := range b.Members {
+		if strings.EqualFold(strings.TrimSpace(m.Username), ownerUsername) {
+			ownerRole = validRole(m.RoleKey)
+		}
+	}
+	// Only the importer joins directly; everyone else found gets a pending
+	// invite with their bundle role, so nobody is added without consent
+	memberRank, err := memberRankFor(ctx, tx, ownerID, projectID, nil)
+	if err != nil {
+		return "", ImportResult{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into projects_members (project_id, user_id, username, role_key, rank)
+		values ($1::uuid, $2::uuid, $3, $4, $5)
+	`, projectID, ownerID, ownerUsername, ownerRole, memberRank); err != nil {
+		return "", ImportResult{}, err
+	}
+	members := map[string]string{strings.ToLower(ownerUsername): ownerID} // lower(username) -> user id
+
+	inviteIDs := map[string]string{} // lower(username) -> invite id
+	var invited []string
+	for _, m := range b.Members {
+		u, ok := users[strings.ToLower(strings.TrimSpace(m.Username))]
+		key := strings.ToLower(u.Username)
+		if !ok || members[key] != "" || inviteIDs[key] != "" {
+			continue
+		}
+		var inviteID string
+		if err := tx.QueryRow(ctx, `
+			insert into project_invites (project_id, inviter_id, invitee_id, role_key, status)
+			values ($1::uuid, $2::uuid, $3::uuid, $4, 'pending')
+			returning id::text
+		`, projectID, ownerID, u.ID, validRole(m.RoleKey)).Scan(&inviteID); err != nil {
+			return "", ImportResult{}, err
+		}
+		inviteIDs[key] = inviteID
+		invited = append(invited, u.Username)
+	}
+
+	// people splits usernames into member ids and the invites whose
+	// acceptance brings the assignment along; the rest are reported
+	notMemberSet := map[string]bool{}
+	missingSet := map[string]bool{}
+	people := func(usernames []string) (ids, invites []string) {
+		for _, u := range usernames {
+			key := strings.ToLower(strings.TrimSpace(u))
+			if key == "" {
+				continue
+			}
+			if id, ok := members[key]; ok {
+				ids = append(ids, id)
+			} else if inviteID, ok := inviteIDs[key]; ok {
+				invites = append(invites, inviteID)
+			} else if lu, ok := users[key]; ok {
+				notMemberSet[lu.Username] = true
+			} else {
+				missingSet[strings.TrimSpace(u)] = true
+			}
+		}
+		return ids, invites
+	}
+	for _, m := range b.Members {
+		people([]string{m.Username})
+	}
+	pend := func(invites []string, kind, taskID string, itemID *string) error {
+		if len(invites) == 0 {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `
+			insert into invite_assignments (invite_id, kind, task_id, checklist_item_id)
+			select distinct i::uuid, $2, $3::uuid, $4::uuid from unnest($1::text[]) as i
+		`, invites, kind, taskID, itemID)
+		return err
+	}
+
+	labelIDs := map[string]string{} // lower(name) -> id
+	for _, l := range b.Labels {
+		if labelIDs[strings.ToLower(l.Name)] != "" {
+			continue
+		}
+		var id string
+		if err := tx.QueryRow(ctx, `
+			insert into project_labels (project_id, name, color)
+			values ($1::uuid, $2, $3)
+			returning id::text
+		`, projectID, l.Name, l.Color).Scan(&id); err != nil {
+			return "", ImportResult{}, err
+		}
+		labelIDs[strings.ToLower(l.Name)] = id
+	}
+
+	// Board order: each status column sorted by position, then respread
+	tasks := make([]*BundleTask, len(b.Tasks))
+	for i := range b.Tasks {
+		tasks[i] = &b.Tasks[i]
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Status != tasks[j].Status {
+			return tasks[i].Status < tasks[j].Status
+		}
+		return tasks[i].Position < tasks[j].Position
+	})
+	ranks := make(map[int]string, len(tasks))
+	for start := 0; start < len(tasks); {
+		end := start
+		for end < len(tasks) && tasks[end].Status == tasks[start].Status {
+			end++
+		}
+		for i, r := range rank.Spread(end - start) {
+			ranks[tasks[start+i].Ref] = r
+		}
+		start = end
+	}
+
+	taskIDs := make(map[int]string, len(tasks))
+	maxRef := 0
+	for _, t := range tasks {
+		estimateHours, storyPoints := nonZeroEstimate(t.EstimateHours, t.StoryPoints)
+		var id string
+		if err := tx.QueryRow(ctx, `
+			insert into tasks (project_id, number, title, details, status, difficulty, priority, rank, estimate_hours, story_points)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			returning id::text
+		`, projectID, t.Ref, t.Title, strings.TrimSpace(t.Details), t.Status, t.Difficulty, t.Priority,
+			ranks[t.Ref], estimateHours, storyPoints).Scan(&id); err != nil {
+			return "", ImportResult{}, err
+		}
+		taskIDs[t.Ref] = id
+		maxRef = max(maxRef, t.Ref)
+	}
+
+	for _, t := range tasks {
+		id := taskIDs[t.Ref]
+		if t.ParentRef != nil {
+			if _, err := tx.Exec(ctx, `
+				update tasks set parent_id = $2::uuid where id::text = $1
+			`, id, taskIDs[*t.ParentRef]); err != nil {
+				return "", ImportResult{}, err
+			}
+		}
+
+		ids, invites := people(t.Assignees)
+		if len(ids) > 0 {
+			if _, err := setTaskAssignees(ctx, tx, id, ids); err != nil {
+				return "", ImportResult{}, err
+			}
+		}
+		if err := pend(invites, pendingAssignee, id, nil); err != nil {
+			return "", ImportResult{}, err
+		}
+		ids, invites = people(t.Watchers)
+		if len(ids) > 0 {
+			if _, err := tx.Exec(ctx, `
+				insert into task_watchers (task_id, user_id)
+				select $1::uuid, u::uuid from unnest($2::text[]) as u
+				on conflict do nothing
+			`, id, ids); err != nil {
+				return "", ImportResult{}, err
+			}
+		}
+		if err := pend(invites, pendingWatcher, id, nil); err != nil {
+			return "", ImportResult{}, err
+		}
+
+		var labels []string
+		for _, name := range t.Labels {
+			if lid, ok := labelIDs[strings.ToLower(strings.TrimSpace(name))]; ok {
+				labels = append(labels, lid)
+			}
+		}
+		if len(labels) > 0 {
+			if _, err := tx.Exec(ctx, `
+				insert into task_labels (task_id, label_id)
+				select $1::uuid, l::uuid from unnest($2::text[]) as l
+				on conflict do nothing
+			`, id, labels); err != nil {
+				return "", ImportResult{}, err
+			}
+		}
+
+		itemRanks := rank.Spread(len(t.Checklist))
+		for i, item := range t.Checklist {
+			var assignee *string
+			var invites []string
+			if item.Assignee != nil {
+				var ids []string
+				ids, invites = people([]string{*item.Assignee})
+				if len(ids) == 1 {
+					assignee = &ids[0]
+				}
+			}
+			var itemID string
+			if err := tx.QueryRow(ctx, `
+				insert into task_checklist_items (task_id, title, is_done, assignee_id, rank)
+				values ($1::uuid, $2, $3, $4::uuid, $5)
+				returning id::text
+			`, id, strings.TrimSpace(item.Title), item.IsDone, assignee, itemRanks[i]).Scan(&itemID); err != nil {
+				return "", ImportResult{}, err
+			}
+			if err := pend(invites, pendingChecklist, id, &itemID); err != nil {
+				return "", ImportResult{}, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update projects set task_seq = $2 where id::text = $1
+	`, projectID, maxRef); err != nil {
+		return "", ImportResult{}, err
+	}
+
+	sorted := func(names []string) []string {
+		if names == nil {
+			names = []string{}
+		}
+		sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+		return names
+	}
+	keys := func(set map[string]bool) []string {
+		out := []string{}
+		for k := range set {
+			out = append(out, k)
+		}
+		return out
+	}
+	return projectID, ImportResult{
+		InvitedUsers:   sorted(invited),
+		NotMemberUsers: sorted(keys(notMemberSet)),
+		MissingUsers:   sorted(keys(missingSet)),
+	}, nil
+}
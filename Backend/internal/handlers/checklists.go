@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ========= Checklist DTOs (responses) =========
+type ChecklistItem struct {
+	ID               string  `json:"id"`
+	TaskID           string  `json:"task_id"`
+	Title            string  `json:"title"`
+	IsDone           bool    `json:"is_done"`
+	AssigneeID       *string `json:"assignee_id"`
+	AssigneeUsername *string `json:"assignee_username"`
+	SortIndex        int     `json:"sort_index"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+// ========= Requests =========
+type createChecklistItemReq struct {
+	Title      string  `json:"title"`
+	AssigneeID *string `json:"assignee_id"`
+	SortIndex  *int    `json:"sort_index"`
+}
+
+type updateChecklistItemReq struct {
+	Title      *string `json:"title"`
+	IsDone     *bool   `json:"is_done"`
+	AssigneeID *string `json:"assignee_id"`
+	SortIndex  *int    `json:"sort_index"`
+}
+
+func (h *Handler) ListChecklist(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, false) {
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+checklistColumns+`
+		from task_checklist_items ci
+		left join users u on u.id = ci.assignee_id
+		where ci.task_id::text = $1
+		order by ci.rank asc, ci.id asc
+	`, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []ChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) AddChecklistItem(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	var req createChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing title"})
+		return
+	}
+	if req.SortIndex != nil && *req.SortIndex < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_index"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireTaskAccess(c, ctx, tx, projectID, taskID, uid, true) {
+		return
+	}
+
+	var assignee any = nil
+	if req.AssigneeID != nil {
+		if a := strings.ToLower(strings.TrimSpace(*req.AssigneeID)); a != "" {
+			if _, err := uuid.Parse(a); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee"})
+				return
+			}
+			if !requireAssignableMember(c, ctx, tx, projectID, a) {
+				return
+			}
+			assignee = a
+		}
+	}
+
+	rank, err := checklistRankFor(ctx, tx, taskID, "", req.SortIndex)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	var itemID string
+	if err := tx.QueryRow(ctx, `
+		insert into task_checklist_items (task_id, title, assignee_id, rank)
+		values ($1::uuid, $2, $3, $4)
+		returning id::text
+	`, taskID, title, assignee, rank).Scan(&itemID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if !finishChecklistChange(c, ctx, tx, taskID) {
+		return
+	}
+
+	out, err := loadChecklistItem(ctx, h.DB, taskID, itemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) UpdateChecklistItem(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+	itemUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("itemId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+	itemID := itemUUID.String()
+
+	var req updateChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Title != nil {
+		t := strings.TrimSpace(*req.Title)
+		if t == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing title"})
+			return
+		}
+		req.Title = &t
+	}
+	if req.SortIndex != nil && *req.SortIndex < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_index"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireTaskAccess(c, ctx, tx, projectID, taskID, uid, true) {
+		return
+	}
+
+	// Assignee: omitted keeps, "" clears, a user id assigns
+	assigneeMode := "keep"
+	var assigneeVal any = nil
+	if req.AssigneeID != nil {
+		if a := strings.ToLower(strings.TrimSpace(*req.AssigneeID)); a == "" {
+			assigneeMode = "null"
+		} else {
+			if _, err := uuid.Parse(a); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee"})
+				return
+			}
+			if !requireAssignableMember(c, ctx, tx, projectID, a) {
+				return
+			}
+			assigneeMode = "set"
+			assigneeVal = a
+		}
+	}
+
+	var newRank *string
+	if req.SortIndex != nil {
+		r, err := checklistRankFor(ctx, tx, taskID, itemID, req.SortIndex)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		newRank = &r
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		update task_checklist_items
+		set
+			title = coalesce($3, title),
+			is_done = coalesce($4, is_done),
+			rank = coalesce($5, rank),
+			assignee_id = case
+				when $6 = 'keep' then assignee_id
+				when $6 = 'null' then null
+				else $7::uuid
+			end
+		where task_id::text = $1 and id::text = $2
+	`, taskID, itemID, req.Title, req.IsDone, newRank, assigneeMode, assigneeVal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "checklist item not found"})
+		return
+	}
+
+	if !finishChecklistChange(c, ctx, tx, taskID) {
+		return
+	}
+
+	out, err := loadChecklistItem(ctx, h.DB, taskID, itemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) DeleteChecklistItem(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+	itemUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("itemId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireTaskAccess(c, ctx, tx, projectID, taskID, uid, true) {
+		return
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		delete from task_checklist_items
+		where task_id::text = $1 and id::text = $2
+	`, taskID, itemUUID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "checklist item not found"})
+		return
+	}
+
+	if !finishChecklistChange(c, ctx, tx, taskID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// finishChecklistChange bumps the task's version, since its checklist counts
+// are part of the task representation, and commits.
+func finishChecklistChange(c *gin.Context, ctx context.Context, tx pgx.Tx, taskID string) bool {
+	if _, err := tx.Exec(ctx, `
+		update tasks set version = version + 1 where id::text = $1
+	`, taskID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	return true
+}
+
+// checklistColumns lists the columns scanChecklistItem expects. Queries must
+// alias task_checklist_items as ci and left join the assignee as u.
+const checklistColumns = `
+	ci.id::text,
+	ci.task_id::text,
+	ci.title,
+	ci.is_done,
+	coalesce(ci.assignee_id::text, ''),
+	coalesce(u.username, ''),
+	` + checklistPosition + `,
+	ci.created_at`
+
+// loadChecklistItem returns pgx.ErrNoRows when the item is not on the task.
+func loadChecklistItem(ctx context.Context, q querier, taskID, itemID string) (ChecklistItem, error) {
+	return scanChecklistItem(q.QueryRow(ctx, `
+		select `+checklistColumns+`
+		from task_checklist_items ci
+		left join users u on u.id = ci.assignee_id
+		where ci.task_id::text = $1 and ci.id::text = $2
+	`, taskID, itemID))
+}
+
+func scanChecklistItem(row pgx.Row) (ChecklistItem, error) {
+	var item ChecklistItem
+	var assigneeID, assigneeUsername string
+	var createdAt time.Time
+	if err := row.Scan(
+		&item.ID,
+		&item.TaskID,
+		&item.Title,
+		&item.IsDone,
+		&assigneeID,
+		&assigneeUsername,
+		&item.SortIndex,
+		&createdAt,
+	); err != nil {
+		return ChecklistItem{}, err
+	}
+
+	if assigneeID != "" {
+		item.AssigneeID = &assigneeID
+	}
+	if assigneeUsername != "" {
+		item.AssigneeUsername = &assigneeUsername
+	}
+	item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	return item, nil
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion parses the If-Match header. A nil version means the client
+// did not ask for a precondition ("*" or no header). On a malformed header it
+// responds 400 and returns false.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return nil, true
+	}
+
+	raw = strings.TrimPrefix(raw, "W/")
+	raw = strings.Trim(raw, `"`)
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid If-Match"})
+		return nil, false
+	}
+	return &v, true
+}
+
+// versionConflict answers 412 with the current state so the client can offer
+// a merge.
+func versionConflict(c *gin.Context, version int, current any) {
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "version conflict", "current": current})
+}
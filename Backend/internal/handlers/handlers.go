@@ -2,21 +2,101 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"time"
 
+	"forge-api/internal/storage"
+
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Handler struct {
 	DB        *pgxpool.Pool
 	JWTSecret []byte
+	Storage   storage.Storage
+	Uploads   UploadLimits
 }
 
-func New(db *pgxpool.Pool, jwtSecret []byte) *Handler {
-	return &Handler{DB: db, JWTSecret: jwtSecret}
+func New(db *pgxpool.Pool, jwtSecret []byte, store storage.Storage) *Handler {
+	return &Handler{DB: db, JWTSecret: jwtSecret, Storage: store, Uploads: DefaultUploadLimits}
 }
 
 func contextTimeout(c *gin.Context, d time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(c.Request.Context(), d)
-}
\ No newline at end of file
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx so the lookup
+// helpers below can run inside or outside a transaction.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// isProjectMember treats projects sitting in the trash as having no members.
+func isProjectMember(ctx context.Context, q querier, projectID, userID string) (bool, error) {
+	var ok bool
+	err := q.QueryRow(ctx, `
+		select exists (
+			select 1
+			from projects_members pm
+			join projects p on p.id = pm.project_id
+			where pm.project_id::text = $1
+			and pm.user_id::text = $2
+			and p.deleted_at is null
+		)
+	`, projectID, userID).Scan(&ok)
+	return ok, err
+}
+
+// projectOwnerID returns pgx.ErrNoRows when the project does not exist or is
+// in the trash.
+func projectOwnerID(ctx context.Context, q querier, projectID string) (string, error) {
+	var ownerID string
+	err := q.QueryRow(ctx, `
+		select owner_id::text from projects where id::text = $1 and deleted_at is null
+	`, projectID).Scan(&ownerID)
+	return ownerID, err
+}
+
+// requireWritableProject responds and returns false when the project is
+// missing, trashed or archived. Archived projects are read-only.
+func requireWritableProject(c *gin.Context, ctx context.Context, q querier, projectID string) bool {
+	var archived bool
+	err := q.QueryRow(ctx, `
+		select archived_at is not null
+		from projects
+		where id::text = $1 and deleted_at is null
+	`, projectID).Scan(&archived)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+
+	if archived {
+		c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		return false
+	}
+
+	return true
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a Postgres
+// foreign_key_violation.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
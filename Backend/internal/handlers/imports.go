@@ -0,0 +1,702 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"forge-api/internal/rank"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Task imports are two steps: the upload is parsed and staged as a preview,
+// then confirmed with the final column-to-status mapping. Only rows without
+// errors are created; the rest come back in the report.
+const (
+	ImportFormatTrello = "trello"
+	ImportFormatCSV    = "csv"
+
+	maxImportBytes = 10 << 20
+	maxImportRows  = 2000
+)
+
+// importRow is one staged card or CSV line. Errors holds problems found while
+// parsing; mapping and member checks happen on every preview and confirm.
+type importRow struct {
+	Row        int      `json:"row"`
+	Title      string   `json:"title"`
+	Details    string   `json:"details"`
+	Column     string   `json:"column"` // Trello list or CSV status
+	Assignees  []string `json:"assignees"`
+	Difficulty int      `json:"difficulty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ========= Import DTOs (responses) =========
+type ImportPreview struct {
+	ID         string            `json:"id"`
+	Format     string            `json:"format"`
+	Filename   string            `json:"filename"`
+	Columns    []ImportColumn    `json:"columns"`
+	Rows       []ImportRowResult `json:"rows"`
+	ValidCount int               `json:"valid_count"`
+	ErrorCount int               `json:"error_count"`
+}
+
+// ImportColumn is a distinct list or status value found in the file, with
+// the status it will become (nil until mapped).
+type ImportColumn struct {
+	Name   string  `json:"name"`
+	Status *string `json:"status"`
+	Rows   int     `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Row        int      `json:"row"`
+	Title      string   `json:"title"`
+	Column     string   `json:"column"`
+	Status     string   `json:"status"`
+	Assignees  []string `json:"assignees"`
+	Difficulty int      `json:"difficulty"`
+	Errors     []string `json:"errors"`
+}
+
+type ImportReport struct {
+	Created int               `json:"created"`
+	TaskIDs []string          `json:"task_ids"`
+	Errors  []ImportRowResult `json:"errors"`
+}
+
+// ========= Requests =========
+type confirmImportReq struct {
+	Mapping map[string]string `json:"mapping"` // column name -> status
+}
+
+// PreviewTaskImport stages the uploaded "file" for the project. The format
+// comes from the "format" form field, or else from the file itself.
+func (h *Handler) PreviewTaskImport(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": maxImportBytes})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	if header.Size > maxImportBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": maxImportBytes})
+		return
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unreadable file"})
+		return
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if len(bytes.TrimSpace(data)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "empty file"})
+		return
+	}
+
+	filename := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	format := strings.ToLower(strings.TrimSpace(c.PostForm("format")))
+	if format == "" {
+		format = detectImportFormat(filename, data)
+	}
+
+	var rows []importRow
+	switch format {
+	case ImportFormatTrello:
+		rows, err = parseTrelloBoard(data)
+	case ImportFormatCSV:
+		rows, err = parseTaskCSV(data)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no rows to import"})
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many rows", "max_rows": maxImportRows})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 15*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	members, err := loadMemberUsernames(ctx, h.DB, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	var importID string
+	if err := h.DB.QueryRow(ctx, `
+		insert into task_imports (project_id, user_id, format, filename, rows)
+		values ($1::uuid, $2::uuid, $3, $4, $5)
+		returning id::text
+	`, projectID, uid, format, filename, rows).Scan(&importID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out := buildImportPreview(rows, nil, members)
+	out.ID = importID
+	out.Format = format
+	out.Filename = filename
+
+	c.JSON(http.StatusOK, out)
+}
+
+// ConfirmTaskImport creates the staged rows that are valid under the given
+// mapping; columns left out of it keep their suggested status. An import can
+// be confirmed once, by whoever uploaded it.
+func (h *Handler) ConfirmTaskImport(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, importID, ok := parseImportPath(c)
+	if !ok {
+		return
+	}
+
+	var req confirmImportReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	for _, st := range req.Mapping {
+		if !isValidTaskStatus(st) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mapping"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 30*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireWritableProject(c, ctx, tx, projectID) {
+		return
+	}
+
+	var rows []importRow
+	var confirmed bool
+	if err := tx.QueryRow(ctx, `
+		select rows, confirmed_at is not null
+		from task_imports
+		where project_id::text = $1 and id::text = $2 and user_id::text = $3
+		for update
+	`, projectID, importID, uid).Scan(&rows, &confirmed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if confirmed {
+		c.JSON(http.StatusConflict, gin.H{"error": "import already confirmed"})
+		return
+	}
+
+	members, err := loadMemberUsernames(ctx, tx, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	preview := buildImportPreview(rows, req.Mapping, members)
+	report := ImportReport{TaskIDs: []string{}, Errors: []ImportRowResult{}}
+	var valid []int // indexes into rows and preview.Rows
+	for i, r := range preview.Rows {
+		if len(r.Errors) > 0 {
+			report.Errors = append(report.Errors, r)
+		} else {
+			valid = append(valid, i)
+		}
+	}
+
+	if len(valid) > 0 {
+		// rows keep their file order, appended below each column
+		byStatus := map[string][]int{}
+		for i, row := range valid {
+			st := preview.Rows[row].Status
+			byStatus[st] = append(byStatus[st], i)
+		}
+		ranks := make([]string, len(valid))
+		for status, idx := range byStatus {
+			var last string
+			if err := tx.QueryRow(ctx, `
+				select coalesce(max(rank), '')
+				from tasks
+				where project_id::text = $1 and status = $2
+			`, projectID, status).Scan(&last); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+			for j, r := range rank.Append(last, len(idx)) {
+				ranks[idx[j]] = r
+			}
+		}
+
+		var seq int
+		if err := tx.QueryRow(ctx, `
+			update projects set task_seq = task_seq + $2
+			where id::text = $1
+			returning task_seq
+		`, projectID, len(valid)).Scan(&seq); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		number := seq - len(valid)
+
+		for i, row := range valid {
+			r := preview.Rows[row]
+			number++
+			var taskID string
+			if err := tx.QueryRow(ctx, `
+				insert into tasks (project_id, number, title, details, status, difficulty, priority, rank)
+				values ($1, $2, $3, $4, $5, $6, 'medium', $7)
+				returning id::text
+			`, projectID, number, r.Title, rows[row].Details, r.Status, r.Difficulty, ranks[i]).Scan(&taskID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+
+			if len(r.Assignees) > 0 {
+				ids := make([]string, 0, len(r.Assignees))
+				for _, u := range r.Assignees {
+					ids = append(ids, members[strings.ToLower(u)])
+				}
+				if _, err := setTaskAssignees(ctx, tx, taskID, ids); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+					return
+				}
+			}
+			report.TaskIDs = append(report.TaskIDs, taskID)
+		}
+		report.Created = len(valid)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update task_imports set confirmed_at = now()
+		where id::text = $1
+	`, importID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DiscardTaskImport drops a staged import without creating anything.
+func (h *Handler) DiscardTaskImport(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, importID, ok := parseImportPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from task_imports
+		where project_id::text = $1 and id::text = $2 and user_id::text = $3
+			and confirmed_at is null
+	`, projectID, importID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func parseImportPath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	importID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("importId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid import id"})
+		return "", "", false
+	}
+	return projectID.String(), importID.String(), true
+}
+
+// loadMemberUsernames maps each member's lowercased username to their id.
+func loadMemberUsernames(ctx context.Context, q querier, projectID string) (map[string]string, error) {
+	rows, err := q.Query(ctx, `
+		select lower(u.username), u.id::text
+		from projects_members pm
+		join users u on u.id = pm.user_id
+		where pm.project_id::text = $1
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]string{}
+	for rows.Next() {
+		var name, id string
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		out[name] = id
+	}
+	return out, rows.Err()
+}
+
+// buildImportPreview checks every row against the mapping (falling back to
+// suggestImportStatus) and the project's members.
+func buildImportPreview(rows []importRow, mapping map[string]string, members map[string]string) ImportPreview {
+	out := ImportPreview{Columns: []ImportColumn{}, Rows: make([]ImportRowResult, 0, len(rows))}
+
+	// columns in order of first appearance
+	status := map[string]*string{}
+	index := map[string]int{}
+	for _, r := range rows {
+		i, seen := index[r.Column]
+		if !seen {
+			col := ImportColumn{Name: r.Column}
+			if st, ok := mapping[r.Column]; ok {
+				col.Status = &st
+			} else if st, ok := suggestImportStatus(r.Column); ok {
+				col.Status = &st
+			}
+			status[r.Column] = col.Status
+			i = len(out.Columns)
+			index[r.Column] = i
+			out.Columns = append(out.Columns, col)
+		}
+		out.Columns[i].Rows++
+	}
+
+	for _, r := range rows {
+		res := ImportRowResult{
+			Row:        r.Row,
+			Title:      r.Title,
+			Column:     r.Column,
+			Assignees:  r.Assignees,
+			Difficulty: r.Difficulty,
+			Errors:     append([]string{}, r.Errors...),
+		}
+		if res.Assignees == nil {
+			res.Assignees = []string{}
+		}
+		if st := status[r.Column]; st != nil {
+			res.Status = *st
+		} else {
+			res.Errors = append(res.Errors, fmt.Sprintf("no status for column %q", r.Column))
+		}
+		for _, u := range r.Assignees {
+			if _, ok := members[strings.ToLower(u)]; !ok {
+				res.Errors = append(res.Errors, fmt.Sprintf("assignee %q is not a project member", u))
+			}
+		}
+
+		if len(res.Errors) > 0 {
+			out.ErrorCount++
+		} else {
+			out.ValidCount++
+		}
+		out.Rows = append(out.Rows, res)
+	}
+	return out
+}
+
+// suggestImportStatus guesses a status from common column names.
+func suggestImportStatus(column string) (string, bool) {
+	key := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(column)))
+
+	switch key {
+	case "", "backlog", "todo", "new", "open", "ideas", "upnext", "next":
+		return "backlog", true
+	case "inprogress", "doing", "wip", "started", "review", "inreview", "testing":
+		return "inProgress", true
+	case "blocked", "onhold", "waiting":
+		return "blocked", true
+	case "done", "complete", "completed", "closed", "finished":
+		return "done", true
+	}
+	return "", false
+}
+
+func detectImportFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return ImportFormatTrello
+	case ".csv":
+		return ImportFormatCSV
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return ImportFormatTrello
+	}
+	return ImportFormatCSV
+}
+
+// trelloBoard is the part of Trello's board JSON export the importer reads.
+type trelloBoard struct {
+	Lists []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards []struct {
+		Name      string   `json:"name"`
+		Desc      string   `json:"desc"`
+		IDList    string   `json:"idList"`
+		IDMembers []string `json:"idMembers"`
+		Closed    bool     `json:"closed"`
+		Pos       float64  `json:"pos"`
+	} `json:"cards"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+}
+
+// parseTrelloBoard reads open cards in open lists, in board order. Lists
+// become columns and card members become assignees by Trello username.
+func parseTrelloBoard(data []byte) ([]importRow, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, errors.New("invalid trello export")
+	}
+	if board.Lists == nil || board.Cards == nil {
+		return nil, errors.New("invalid trello export")
+	}
+
+	type list struct {
+		name string
+		pos  float64
+	}
+	lists := map[string]list{}
+	for _, l := range board.Lists {
+		if !l.Closed {
+			lists[l.ID] = list{strings.TrimSpace(l.Name), l.Pos}
+		}
+	}
+	usernames := map[string]string{}
+	for _, m := range board.Members {
+		usernames[m.ID] = m.Username
+	}
+
+	cards := board.Cards[:0]
+	for _, card := range board.Cards {
+		if _, ok := lists[card.IDList]; ok && !card.Closed {
+			cards = append(cards, card)
+		}
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, b := lists[cards[i].IDList], lists[cards[j].IDList]
+		if a.pos != b.pos {
+			return a.pos < b.pos
+		}
+		return cards[i].Pos < cards[j].Pos
+	})
+
+	rows := make([]importRow, 0, len(cards))
+	for i, card := range cards {
+		r := importRow{
+			Row:        i + 1,
+			Title:      strings.TrimSpace(card.Name),
+			Details:    strings.TrimSpace(card.Desc),
+			Column:     lists[card.IDList].name,
+			Difficulty: 2,
+		}
+		for _, id := range card.IDMembers {
+			if u, ok := usernames[id]; ok {
+				r.Assignees = append(r.Assignees, u)
+			} else {
+				r.Errors = append(r.Errors, fmt.Sprintf("unknown trello member %q", id))
+			}
+		}
+		if r.Title == "" {
+			r.Errors = append(r.Errors, "missing title")
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+// parseTaskCSV reads a CSV with a header row naming any of title, details,
+// status, assignee and difficulty (title is required). Assignee may list
+// several usernames separated by ";". Row numbers count the header as 1.
+func parseTaskCSV(data []byte) ([]importRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.New("invalid csv")
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, dup := cols[key]; !dup {
+			cols[key] = i
+		}
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, errors.New("csv has no title column")
+	}
+
+	var rows []importRow
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return nil, fmt.Errorf("invalid csv on line %d", perr.Line)
+			}
+			return nil, errors.New("invalid csv")
+		}
+		if len(rows) >= maxImportRows {
+			return nil, errors.New("too many rows")
+		}
+
+		field := func(name string) string {
+			i, ok := cols[name]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+
+		// blank lines in spreadsheets are common; skip them silently
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+
+		// the physical line the record starts on; a counter would drift
+		// over quoted newlines and skipped blank lines
+		line, _ := r.FieldPos(0)
+		row := importRow{
+			Row:        line,
+			Title:      field("title"),
+			Details:    field("details"),
+			Column:     field("status"),
+			Difficulty: 2,
+		}
+		if row.Title == "" {
+			row.Errors = append(row.Errors, "missing title")
+		}
+		for _, u := range strings.Split(field("assignee"), ";") {
+			if u = strings.TrimPrefix(strings.TrimSpace(u), "@"); u != "" {
+				row.Assignees = append(row.Assignees, u)
+			}
+		}
+		if v := field("difficulty"); v != "" {
+			d, err := strconv.Atoi(v)
+			if err != nil || d < 1 || d > 5 {
+				row.Errors = append(row.Errors, "invalid difficulty")
+			} else {
+				row.Difficulty = d
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
@@ -157,16 +158,14 @@ func (h *Handler) CreateProjectInvite(c *gin.Context) {
 	}
 
 	// 2) Check inviter is member
-	var allowed bool
-	err = h.DB.QueryRow(ctx, `
-        select exists(
-            select 1 from projects_members where project_id::text = $1 and user_id::text = $2
-        )
-    `, projectID, inviterID).Scan(&allowed)
+	allowed, err := isProjectMember(ctx, h.DB, projectID, inviterID)
 	if err != nil || !allowed {
 		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
 		return
 	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
 
 	// 3) Check invitee already a member
 	var isMember bool
@@ -347,6 +346,7 @@ func (h *Handler) ListMyInvites(c *gin.Context) {
 		join users inv on inv.id = pi.inviter_id
         where pi.invitee_id::text = $1
 			and pi.status::text = $2
+			and p.deleted_at is null
         order by pi.created_at desc
         limit 50
     `, myID, status)
@@ -415,6 +415,9 @@ func (h *Handler) AcceptInvite(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"error": "invite not pending"})
 		return
 	}
+	if !requireWritableProject(c, ctx, tx, projectID) {
+		return
+	}
 
 	// insert membership
 	// NOTE: use user's username from users table (or join profiles); simplest:
@@ -424,148 +427,44 @@ func (h *Handler) AcceptInvite(c *gin.Context) {
 		return
 	}
 
-	_, err = tx.Exec(ctx, `
-        insert into projects_members (project_id, user_id, username, role_key)
-        values ($1::uuid, $2::uuid, $3, $4)
-        on conflict do nothing
-    `, projectID, myID, username, roleKey)
+	// joined projects go to the end of the user's list
+	memberRank, err := memberRankFor(ctx, tx, myID, projectID, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	// delete when invite accepted
 	_, err = tx.Exec(ctx, `
-        delete from project_invites
-        where id::text = $1
-    `, inviteID)
+        insert into projects_members (project_id, user_id, username, role_key, rank)
+        values ($1::uuid, $2::uuid, $3, $4, $5)
+        on conflict do nothing
+    `, projectID, myID, username, roleKey, memberRank)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	// 1) Fetch the project details to return
-	var project Project
-	if err := tx.QueryRow(ctx, `
-        select
-			p.id::text,
-			p.name,
-			p.description,
-			p.owner_id::text,
-			p.is_pinned,
-			p.sort_index
-		from projects p
-		where p.id::text = $1
-    `, projectID).Scan(&project.ID, &project.Name, &project.Description, &project.OwnerId, &project.IsPinned, &project.SortIndex); err != nil {
+	if err := applyInviteAssignments(ctx, tx, inviteID, myID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	// 2) Fetch all members for the project ID
-	memRows, err := tx.Query(ctx, `
-		select
-			pm.user_id::text,
-			pm.username,
-			pm.role_key
-		from projects_members pm
-		where pm.project_id::text = $1
-		order by lower(pm.username) asc
-	`, projectID)
+	// delete when invite accepted
+	_, err = tx.Exec(ctx, `
+        delete from project_invites
+        where id::text = $1
+    `, inviteID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
-	defer memRows.Close()
 
-	project.Members = []Member{}
-	for memRows.Next() {
-		var m Member
-		if err := memRows.Scan(&m.ID, &m.Username, &m.RoleKey); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-			return
-		}
-		project.Members = append(project.Members, m)
-	}
-
-	if err := memRows.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-		return
-	}
-
-	// 3) Fetch all tasks for the project ID
-	taskRows, err := tx.Query(ctx, `
-		select
-			t.id::text,
-			t.title,
-			coalesce(t.details, ''),
-			t.status,
-			coalesce(t.assignee_id::text, ''),
-			coalesce(u.username, ''),
-			t.difficulty,
-			t.sort_index,
-			t.created_at
-		from tasks t
-		left join users u on u.id = t.assignee_id
-		where t.project_id::text = $1
-		order by
-			case t.status
-				when 'backlog' then 1
-				when 'inProgress' then 2
-				when 'blocked' then 3
-				when 'done' then 4
-				else 9
-			end,
-			t.sort_index asc,
-			t.created_at asc
-	`, projectID)
+	// Fetch the project (members + tasks) to return
+	project, err := loadProject(ctx, tx, projectID, myID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
-	defer taskRows.Close()
-
-	project.Tasks = []Task{}
-	for taskRows.Next() {
-		var t Task
-		var assigneeID string
-		var assigneeUsername string
-		var createdAt time.Time
-
-		if err := taskRows.Scan(
-			&t.ID,
-			&t.Title,
-			&t.Details,
-			&t.Status,
-			&assigneeID,
-			&assigneeUsername,
-			&t.Difficulty,
-			&t.SortIndex,
-			&createdAt,
-		); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-			return
-		}
-
-		if assigneeID != "" {
-			t.AssigneeID = &assigneeID
-		}
-		if assigneeUsername != "" {
-			t.AssigneeUsername = &assigneeUsername
-		}
-		t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
-
-		project.Tasks = append(project.Tasks, t)
-	}
-
-	if err := taskRows.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-		return
-	}
-
-	if err := memRows.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-		return
-	}
 
 	if err := tx.Commit(ctx); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
@@ -644,3 +543,39 @@ func (h *Handler) DeleteInvite(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
+
+// applyInviteAssignments hands userID the assignments an imported project
+// kept for their invite. Checklist items someone else took meanwhile stay
+// theirs. The rows go with the invite.
+func applyInviteAssignments(ctx context.Context, tx pgx.Tx, inviteID, userID string) error {
+	if _, err := tx.Exec(ctx, `
+		insert into task_assignees (task_id, user_id)
+		select ia.task_id, $2::uuid
+		from invite_assignments ia
+		where ia.invite_id::text = $1 and ia.kind = 'assignee'
+		on conflict do nothing
+	`, inviteID, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		insert into task_watchers (task_id, user_id)
+		select ia.task_id, $2::uuid
+		from invite_assignments ia
+		where ia.invite_id::text = $1 and ia.kind = 'watcher'
+		on conflict do nothing
+	`, inviteID, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		update task_checklist_items ci
+		set assignee_id = $2::uuid
+		from invite_assignments ia
+		where ia.invite_id::text = $1
+			and ia.kind = 'checklist'
+			and ci.id = ia.checklist_item_id
+			and ci.assignee_id is null
+	`, inviteID, userID)
+	return err
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Project keys are 2-10 uppercase letters and digits, starting with a letter.
+var projectKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+const fallbackProjectKey = "PRJ"
+
+// normalizeProjectKey upper-cases and checks a client-supplied key.
+func normalizeProjectKey(raw string) (string, bool) {
+	key := strings.ToUpper(strings.TrimSpace(raw))
+	return key, projectKeyRe.MatchString(key)
+}
+
+// deriveProjectKey builds a key from the first three letters and digits of
+// a project name, matching the backfill in init.sql.
+func deriveProjectKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			if b.Len() == 3 {
+				break
+			}
+		}
+	}
+	if key := b.String(); projectKeyRe.MatchString(key) {
+		return key
+	}
+	return fallbackProjectKey
+}
+
+func taskKey(projectKey string, number int) string {
+	return projectKey + "-" + strconv.Itoa(number)
+}
+
+// parseTaskKey splits "FRG-42" into its project key and task number.
+func parseTaskKey(s string) (string, int, bool) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return "", 0, false
+	}
+	key, ok := normalizeProjectKey(s[:i])
+	if !ok {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return key, n, true
+}
+
+// ResolveTaskKey lets routes with :projectId and :taskId take a task key
+// like "FRG-42" in place of the task's UUID, rewriting the param before the
+// handler sees it. Keys only resolve within the project in the URL, only
+// against the project's current key and only for its members. Finer
+// access checks are left to the handlers.
+func (h *Handler) ResolveTaskKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.Param("taskId"))
+		if raw == "" {
+			c.Next()
+			return
+		}
+		if _, err := uuid.Parse(raw); err == nil {
+			c.Next()
+			return
+		}
+		key, number, ok := parseTaskKey(raw)
+		if !ok {
+			c.Next() // the handler reports the bad id
+			return
+		}
+		projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		uid, ok := getAuthUID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		ctx, cancel := contextTimeout(c, 5*time.Second)
+		defer cancel()
+
+		// check membership first so a missing key and someone else's project
+		// look the same to outsiders
+		allowed, err := isProjectMember(ctx, h.DB, projectUUID.String(), uid)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+			return
+		}
+
+		var taskID string
+		if err := h.DB.QueryRow(ctx, `
+			select t.id::text
+			from tasks t
+			join projects p on p.id = t.project_id
+			where p.id = $1 and p.key = $2 and t.number = $3
+		`, projectUUID, key, number).Scan(&taskID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "task not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+
+		for i := range c.Params {
+			if c.Params[i].Key == "taskId" {
+				c.Params[i].Value = taskID
+			}
+		}
+		c.Next()
+	}
+}
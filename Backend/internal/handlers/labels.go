@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var labelColorRe = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+const defaultLabelColor = "#6b7280"
+
+// ========= Label DTOs (responses) =========
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// ========= Requests =========
+type createLabelReq struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type updateLabelReq struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+func (h *Handler) ListLabels(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	labels, err := loadProjectLabels(ctx, h.DB, []string{projectID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out := labels[projectID]
+	if out == nil {
+		out = []Label{}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) CreateLabel(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req createLabelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+	color := strings.ToLower(strings.TrimSpace(req.Color))
+	if color == "" {
+		color = defaultLabelColor
+	}
+	if !labelColorRe.MatchString(color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid color"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	out := Label{Name: name, Color: color}
+	if err := h.DB.QueryRow(ctx, `
+		insert into project_labels (project_id, name, color)
+		values ($1::uuid, $2, $3)
+		returning id::text
+	`, projectID, name, color).Scan(&out.ID); err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "label already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) UpdateLabel(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, labelID, ok := parseLabelPath(c)
+	if !ok {
+		return
+	}
+
+	var req updateLabelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+			return
+		}
+		req.Name = &n
+	}
+	if req.Color != nil {
+		col := strings.ToLower(strings.TrimSpace(*req.Color))
+		if !labelColorRe.MatchString(col) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid color"})
+			return
+		}
+		req.Color = &col
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	var out Label
+	if err := h.DB.QueryRow(ctx, `
+		update project_labels
+		set name = coalesce($3, name),
+			color = coalesce($4, color)
+		where project_id::text = $1 and id::text = $2
+		returning id::text, name, color
+	`, projectID, labelID, req.Name, req.Color).Scan(&out.ID, &out.Name, &out.Color); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "label not found"})
+			return
+		}
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "label already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// DeleteLabel removes the label from the project and from every task.
+func (h *Handler) DeleteLabel(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, labelID, ok := parseLabelPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from project_labels
+		where project_id::text = $1 and id::text = $2
+	`, projectID, labelID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "label not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func parseLabelPath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	labelID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("labelId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label id"})
+		return "", "", false
+	}
+	return projectID.String(), labelID.String(), true
+}
+
+// normalizeLabelIDs lowercases and dedupes label ids; false means one of them
+// is not a UUID.
+func normalizeLabelIDs(raw []string) ([]string, bool) {
+	seen := make(map[string]bool, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, id := range raw {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, true
+}
+
+// setTaskLabels replaces a task's labels. It returns false, changing nothing,
+// when any of labelIDs is not a label of the project.
+func setTaskLabels(ctx context.Context, tx pgx.Tx, projectID, taskID string, labelIDs []string) (bool, error) {
+	var known int
+	if err := tx.QueryRow(ctx, `
+		select count(*)
+		from project_labels
+		where project_id::text = $1 and id::text = any($2)
+	`, projectID, labelIDs).Scan(&known); err != nil {
+		return false, err
+	}
+	if known != len(labelIDs) {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from task_labels where task_id::text = $1
+	`, taskID); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into task_labels (task_id, label_id)
+		select $1::uuid, l::uuid from unnest($2::text[]) as l
+	`, taskID, labelIDs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func loadProjectLabels(ctx context.Context, q querier, projectIDs []string) (map[string][]Label, error) {
+	rows, err := q.Query(ctx, `
+		select project_id::text, id::text, name, color
+		from project_labels
+		where project_id::text = any($1)
+		order by lower(name) asc, id asc
+	`, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Label, len(projectIDs))
+	for rows.Next() {
+		var projectID string
+		var l Label
+		if err := rows.Scan(&projectID, &l.ID, &l.Name, &l.Color); err != nil {
+			return nil, err
+		}
+		out[projectID] = append(out[projectID], l)
+	}
+	return out, rows.Err()
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 // ========= Requests =========
@@ -47,6 +49,10 @@ func (h *Handler) UpdateMemberRole(c *gin.Context) {
 		return
 	}
 
+	if !requireWritableProject(c, ctx, h.DB, projectId) {
+		return
+	}
+
 	cmd, err := h.DB.Exec(ctx,
 		`update projects_members
 			set role_key = $1
@@ -87,13 +93,17 @@ func (h *Handler) DeleteMember(c *gin.Context) {
 		return
 	}
 
-	// Check if myID matches the owner_id of the project
-	var ownerID string
-	err := h.DB.QueryRow(ctx, `
-		SELECT owner_id FROM projects WHERE id = $1
-	`, projectId).Scan(&ownerID)
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// The owner, or an admin of the project's organization, may remove members
+	ownerID, err := projectOwnerID(ctx, tx, projectId)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
 			return
 		}
@@ -101,25 +111,148 @@ func (h *Handler) DeleteMember(c *gin.Context) {
 		return
 	}
 
-	if myID != ownerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+	if myID != ownerID && !requireProjectManager(c, ctx, tx, projectId, myID) {
 		return
 	}
 
-	cmd, err := h.DB.Exec(ctx,
-		`delete from projects_members
-			where project_id = $1 and
-			user_id = $2
-		`, projectId, memberId)
+	if memberId == ownerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot remove the project owner"})
+		return
+	}
+
+	removed, err := removeProjectMember(ctx, tx, projectId, memberId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	if cmd.RowsAffected() == 0 {
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// LeaveProject removes the caller from a project they don't own. The owner
+// has to transfer ownership first.
+func (h *Handler) LeaveProject(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectId := strings.TrimSpace(c.Param("projectId"))
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	ownerID, err := projectOwnerID(ctx, tx, projectId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if myID == ownerID {
+		c.JSON(http.StatusConflict, gin.H{"error": "owner must transfer ownership before leaving"})
+		return
+	}
+
+	removed, err := removeProjectMember(ctx, tx, projectId, myID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if !removed {
 		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
 		return
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
+
+// removeProjectMember drops a membership along with anything that only made
+// sense while the user was on the project: their task assignments and any
+// ownership transfer offered to them.
+func removeProjectMember(ctx context.Context, tx pgx.Tx, projectID, userID string) (bool, error) {
+	cmd, err := tx.Exec(ctx, `
+		delete from projects_members
+		where project_id::text = $1 and user_id::text = $2
+	`, projectID, userID)
+	if err != nil {
+		return false, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from task_assignees ta
+		using tasks t
+		where t.id = ta.task_id
+			and t.project_id::text = $1
+			and ta.user_id::text = $2
+	`, projectID, userID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from task_watchers tw
+		using tasks t
+		where t.id = tw.task_id
+			and t.project_id::text = $1
+			and tw.user_id::text = $2
+	`, projectID, userID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update task_checklist_items ci
+		set assignee_id = null
+		from tasks t
+		where t.id = ci.task_id
+			and t.project_id::text = $1
+			and ci.assignee_id::text = $2
+	`, projectID, userID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from project_transfers
+		where project_id::text = $1 and to_user_id::text = $2
+	`, projectID, userID); err != nil {
+		return false, err
+	}
+
+	if err := stopProjectTimers(ctx, tx, projectID, userID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
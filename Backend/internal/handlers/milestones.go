@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ========= Milestone DTOs (responses) =========
+type Milestone struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	DueDate     *string `json:"due_date"` // YYYY-MM-DD
+	TaskCount   int     `json:"task_count"`
+	DoneCount   int     `json:"done_count"`
+	Progress    int     `json:"progress"` // percent of its tasks done
+}
+
+// ========= Requests =========
+type createMilestoneReq struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	DueDate     *string `json:"due_date"`
+}
+
+type updateMilestoneReq struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	DueDate     *string `json:"due_date"` // "" clears
+}
+
+func (h *Handler) ListMilestones(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+milestoneColumns+`
+		from milestones m
+		where m.project_id::text = $1
+		order by m.due_date nulls last, lower(m.name), m.id
+	`, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []Milestone{}
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) CreateMilestone(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req createMilestoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+	var due *time.Time
+	if req.DueDate != nil && strings.TrimSpace(*req.DueDate) != "" {
+		t, err := time.Parse(time.DateOnly, strings.TrimSpace(*req.DueDate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date"})
+			return
+		}
+		due = &t
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	var milestoneID string
+	if err := h.DB.QueryRow(ctx, `
+		insert into milestones (project_id, name, description, due_date)
+		values ($1::uuid, $2, $3, $4)
+		returning id::text
+	`, projectID, name, strings.TrimSpace(req.Description), due).Scan(&milestoneID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadMilestone(ctx, h.DB, projectID, milestoneID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) UpdateMilestone(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, milestoneID, ok := parseMilestonePath(c)
+	if !ok {
+		return
+	}
+
+	var req updateMilestoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+			return
+		}
+		req.Name = &n
+	}
+	if req.Description != nil {
+		d := strings.TrimSpace(*req.Description)
+		req.Description = &d
+	}
+	var due *time.Time
+	if req.DueDate != nil {
+		if v := strings.TrimSpace(*req.DueDate); v != "" {
+			t, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date"})
+				return
+			}
+			due = &t
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		update milestones
+		set name = coalesce($3, name),
+			description = coalesce($4, description),
+			due_date = case when $5 then $6::date else due_date end
+		where project_id::text = $1 and id::text = $2
+	`, projectID, milestoneID, req.Name, req.Description, req.DueDate != nil, due)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "milestone not found"})
+		return
+	}
+
+	out, err := loadMilestone(ctx, h.DB, projectID, milestoneID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// DeleteMilestone removes the milestone; its tasks are kept.
+func (h *Handler) DeleteMilestone(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, milestoneID, ok := parseMilestonePath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from milestones
+		where project_id::text = $1 and id::text = $2
+	`, projectID, milestoneID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "milestone not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// requireMilestone checks milestoneID is one of the project's milestones.
+func requireMilestone(c *gin.Context, ctx context.Context, q querier, projectID, milestoneID string) bool {
+	if _, err := uuid.Parse(milestoneID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid milestone_id"})
+		return false
+	}
+
+	var exists bool
+	if err := q.QueryRow(ctx, `
+		select exists (
+			select 1 from milestones
+			where project_id::text = $1 and id::text = $2
+		)
+	`, projectID, milestoneID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "milestone not found"})
+		return false
+	}
+	return true
+}
+
+func parseMilestonePath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	milestoneID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("milestoneId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid milestone id"})
+		return "", "", false
+	}
+	return projectID.String(), milestoneID.String(), true
+}
+
+const milestoneColumns = `
+	m.id::text,
+	m.project_id::text,
+	m.name,
+	m.description,
+	m.due_date,
+	(select count(*) from tasks t where t.milestone_id = m.id)::int,
+	(select count(*) from tasks t where t.milestone_id = m.id and t.status = 'done')::int
+`
+
+// loadMilestone returns pgx.ErrNoRows when the milestone is not in the
+// project.
+func loadMilestone(ctx context.Context, q querier, projectID, milestoneID string) (Milestone, error) {
+	return scanMilestone(q.QueryRow(ctx, `
+		select `+milestoneColumns+`
+		from milestones m
+		where m.project_id::text = $1 and m.id::text = $2
+	`, projectID, milestoneID))
+}
+
+func scanMilestone(row pgx.Row) (Milestone, error) {
+	var m Milestone
+	var due *time.Time
+	if err := row.Scan(
+		&m.ID,
+		&m.ProjectID,
+		&m.Name,
+		&m.Description,
+		&due,
+		&m.TaskCount,
+		&m.DoneCount,
+	); err != nil {
+		return Milestone{}, err
+	}
+	if due != nil {
+		v := due.Format(time.DateOnly)
+		m.DueDate = &v
+	}
+	if m.TaskCount > 0 {
+		m.Progress = m.DoneCount * 100 / m.TaskCount
+	}
+	return m, nil
+}
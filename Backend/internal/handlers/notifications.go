@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Notification kinds
+const (
+	NotificationTaskAssigned = "task_assigned"
+	NotificationTaskUpdated  = "task_updated"
+	NotificationTaskDeleted  = "task_deleted"
+
+	// one notification for a bulk change; task_count says how many tasks
+	NotificationTasksUpdated = "tasks_updated"
+	NotificationTasksDeleted = "tasks_deleted"
+)
+
+// ========= Notification DTOs (responses) =========
+type Notification struct {
+	ID            string  `json:"id"`
+	Kind          string  `json:"kind"`
+	ProjectID     string  `json:"project_id"`
+	ProjectName   string  `json:"project_name"`
+	TaskID        *string `json:"task_id"`
+	TaskTitle     string  `json:"task_title"`
+	TaskCount     int     `json:"task_count"`
+	ActorID       *string `json:"actor_id"`
+	ActorUsername *string `json:"actor_username"`
+	IsRead        bool    `json:"is_read"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    *string        `json:"next_cursor"`
+}
+
+// ========= Requests =========
+type notificationCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// ListNotifications returns the caller's notifications, newest first.
+// ?unread=true limits it to unread ones.
+func (h *Handler) ListNotifications(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	var afterAt *time.Time
+	var afterID *string
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		var after notificationCursor
+		err := decodeCursor(raw, &after)
+		if err == nil {
+			_, err = uuid.Parse(after.ID) // compared as ::uuid below
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		afterAt = &after.CreatedAt
+		afterID = &after.ID
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select
+			n.id::text,
+			n.kind,
+			n.project_id::text,
+			p.name,
+			coalesce(n.task_id::text, ''),
+			n.task_title,
+			n.task_count,
+			coalesce(n.actor_id::text, ''),
+			coalesce(a.username, ''),
+			n.read_at is not null,
+			n.created_at
+		from notifications n
+		join projects p on p.id = n.project_id
+		left join users a on a.id = n.actor_id
+		where n.user_id::text = $1
+			and (not $2::boolean or n.read_at is null)
+			and ($3::timestamptz is null or (n.created_at, n.id) < ($3::timestamptz, $4::uuid))
+		order by n.created_at desc, n.id desc
+		limit $5
+	`, uid, unreadOnly, afterAt, afterID, limit+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := NotificationPage{Notifications: []Notification{}}
+	var last notificationCursor
+	for rows.Next() {
+		if len(out.Notifications) == limit {
+			out.NextCursor = encodeCursor(last)
+			break
+		}
+
+		var n Notification
+		var taskID, actorID, actorUsername string
+		var createdAt time.Time
+		if err := rows.Scan(
+			&n.ID,
+			&n.Kind,
+			&n.ProjectID,
+			&n.ProjectName,
+			&taskID,
+			&n.TaskTitle,
+			&n.TaskCount,
+			&actorID,
+			&actorUsername,
+			&n.IsRead,
+			&createdAt,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if taskID != "" {
+			n.TaskID = &taskID
+		}
+		if actorID != "" {
+			n.ActorID = &actorID
+			n.ActorUsername = &actorUsername
+		}
+		n.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+		last = notificationCursor{CreatedAt: createdAt, ID: n.ID}
+		out.Notifications = append(out.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("notificationId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		update notifications
+		set read_at = coalesce(read_at, now())
+		where id = $1 and user_id::text = $2
+	`, id, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		update notifications
+		set read_at = now()
+		where user_id::text = $1 and read_at is null
+	`, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "marked": cmd.RowsAffected()})
+}
+
+// notifyUsers records a notification about a task for each of userIDs,
+// skipping the actor.
+func notifyUsers(ctx context.Context, tx pgx.Tx, userIDs []string, taskID, actorID, kind string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return insertNotifications(ctx, tx, `
+		select u::uuid from unnest($4::text[]) as u
+	`, taskID, actorID, kind, userIDs)
+}
+
+// notifyTaskFollowers notifies a task's assignees and watchers, except the
+// actor and anyone in skip (already told something more specific).
+func notifyTaskFollowers(ctx context.Context, tx pgx.Tx, taskID, actorID, kind string, skip []string) error {
+	if skip == nil {
+		skip = []string{}
+	}
+	return insertNotifications(ctx, tx, `
+		select user_id from task_assignees where task_id::text = $1
+		union
+		select user_id from task_watchers where task_id::text = $1
+		except
+		select u::uuid from unnest($4::text[]) as u
+	`, taskID, actorID, kind, skip)
+}
+
+// insertNotifications inserts one notification per recipient selected by
+// recipientsSQL ($1 task id, $4 the extra argument), snapshotting the task
+// title so the notification still reads well after the task is deleted.
+func insertNotifications(ctx context.Context, tx pgx.Tx, recipientsSQL, taskID, actorID, kind string, extra []string) error {
+	_, err := tx.Exec(ctx, `
+		insert into notifications (user_id, kind, project_id, task_id, task_title, actor_id)
+		select r.user_id, $3, t.project_id, t.id, t.title, $2::uuid
+		from (`+recipientsSQL+`) as r(user_id)
+		join tasks t on t.id::text = $1
+		where r.user_id <> $2::uuid
+	`, taskID, actorID, kind, extra)
+	return err
+}
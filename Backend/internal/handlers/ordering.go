@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+
+	"forge-api/internal/rank"
+)
+
+// Positions (sort_index) are still what clients send and receive, but they
+// are derived from the rank column rather than stored, so a move rewrites a
+// single row.
+
+// taskPosition is a task's 0-based position within its board column.
+const taskPosition = `(
+		select count(*)
+		from tasks s
+		where s.project_id = t.project_id
+			and s.status = t.status
+			and (s.rank, s.id) < (t.rank, t.id)
+	)::int`
+
+// memberPosition is a project's 0-based position in the user's own list.
+const memberPosition = `(
+		select count(*)
+		from projects_members s
+		where s.user_id = pm.user_id
+			and (s.rank, s.id) < (pm.rank, pm.id)
+	)::int`
+
+// checklistPosition is a checklist item's 0-based position within its task.
+const checklistPosition = `(
+		select count(*)
+		from task_checklist_items s
+		where s.task_id = ci.task_id
+			and (s.rank, s.id) < (ci.rank, ci.id)
+	)::int`
+
+// rankForPosition returns a rank that places an item at position among its
+// siblings. siblingsSQL must select the siblings' ranks in order, excluding
+// the item being placed. A nil position, or one past the end, appends.
+func rankForPosition(ctx context.Context, q querier, position *int, siblingsSQL string, args ...any) (string, error) {
+	rows, err := q.Query(ctx, siblingsSQL, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var prev, next string
+	for i := 0; rows.Next(); i++ {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			return "", err
+		}
+		if position != nil && i == *position {
+			next = r
+			break
+		}
+		prev = r
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return rank.Between(prev, next), nil
+}
+
+// taskRankFor places a task at position within a project's status column.
+func taskRankFor(ctx context.Context, q querier, projectID, status, excludeTaskID string, position *int) (string, error) {
+	return rankForPosition(ctx, q, position, `
+		select rank
+		from tasks
+		where project_id::text = $1
+			and status = $2
+			and id::text <> $3
+		order by rank, id
+	`, projectID, status, excludeTaskID)
+}
+
+// memberRankFor places a project at position within a user's project list.
+func memberRankFor(ctx context.Context, q querier, userID, excludeProjectID string, position *int) (string, error) {
+	return rankForPosition(ctx, q, position, `
+		select rank
+		from projects_members
+		where user_id::text = $1
+			and project_id::text <> $2
+		order by rank, id
+	`, userID, excludeProjectID)
+}
+
+// checklistRankFor places a checklist item at position within its task.
+func checklistRankFor(ctx context.Context, q querier, taskID, excludeItemID string, position *int) (string, error) {
+	return rankForPosition(ctx, q, position, `
+		select rank
+		from task_checklist_items
+		where task_id::text = $1
+			and id::text <> $2
+		order by rank, id
+	`, taskID, excludeItemID)
+}
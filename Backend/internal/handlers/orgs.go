@@ -0,0 +1,954 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Organization roles. Owners and admins manage every project in the
+// organization; only owners can hand out or take away the owner role, and
+// an organization always keeps at least one owner.
+const (
+	OrgOwner  = "owner"
+	OrgAdmin  = "admin"
+	OrgMember = "member"
+)
+
+// ========= Organization DTOs (responses) =========
+type Organization struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Role         string `json:"role"` // the caller's role
+	MemberCount  int    `json:"member_count"`
+	ProjectCount int    `json:"project_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type OrgMemberInfo struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+// OrgProject is a project in the organization's listing, which org members
+// see whether or not they are on the project.
+type OrgProject struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Key           string `json:"key"`
+	Description   string `json:"description"`
+	OwnerID       string `json:"owner_id"`
+	OwnerUsername string `json:"owner_username"`
+	MemberCount   int    `json:"member_count"`
+	IsArchived    bool   `json:"is_archived"`
+	IsMember      bool   `json:"is_member"`
+}
+
+// ========= Requests =========
+type orgNameReq struct {
+	Name string `json:"name"`
+}
+
+type addOrgMemberReq struct {
+	Username string `json:"username"`
+	Role     string `json:"role"` // defaults to member
+}
+
+type updateOrgMemberReq struct {
+	Role string `json:"role"`
+}
+
+type addOrgProjectReq struct {
+	ProjectID string `json:"project_id"`
+}
+
+func (h *Handler) ListOrgs(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select `+orgColumns+`
+		from org_members me
+		join organizations o on o.id = me.org_id
+		where me.user_id::text = $1
+		order by lower(o.name), o.id
+	`, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []Organization{}
+	for rows.Next() {
+		o, err := scanOrg(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, o)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// CreateOrg makes the caller the organization's first owner.
+func (h *Handler) CreateOrg(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	var req orgNameReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var orgID string
+	if err := tx.QueryRow(ctx, `
+		insert into organizations (name, created_by)
+		values ($1, $2::uuid)
+		returning id::text
+	`, name, uid).Scan(&orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into org_members (org_id, user_id, role)
+		values ($1::uuid, $2::uuid, $3)
+	`, orgID, uid, OrgOwner); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadOrg(ctx, tx, orgID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) GetOrg(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	out, err := loadOrg(ctx, h.DB, orgID, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) UpdateOrg(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	var req orgNameReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgAdmin); !ok {
+		return
+	}
+
+	if _, err := h.DB.Exec(ctx, `
+		update organizations set name = $2 where id::text = $1
+	`, orgID, name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadOrg(ctx, h.DB, orgID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// DeleteOrg is for owners, once every project (including the trash) has
+// been moved out or purged.
+func (h *Handler) DeleteOrg(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgOwner); !ok {
+		return
+	}
+
+	var hasProjects bool
+	if err := h.DB.QueryRow(ctx, `
+		select exists (select 1 from projects where org_id::text = $1)
+	`, orgID).Scan(&hasProjects); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if hasProjects {
+		c.JSON(http.StatusConflict, gin.H{"error": "organization still has projects"})
+		return
+	}
+
+	if _, err := h.DB.Exec(ctx, `
+		delete from organizations where id::text = $1
+	`, orgID); err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "organization still has projects"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) ListOrgMembers(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgMember); !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+orgMemberColumns+`
+		from org_members om
+		join users u on u.id = om.user_id
+		where om.org_id::text = $1
+		order by `+orgRoleRank+`, lower(u.username)
+	`, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []OrgMemberInfo{}
+	for rows.Next() {
+		m, err := scanOrgMember(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// AddOrgMember adds an existing user by username. Admins add members and
+// admins; only owners add owners.
+func (h *Handler) AddOrgMember(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	var req addOrgMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing username"})
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if role == "" {
+		role = OrgMember
+	}
+	if !isValidOrgRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	myRole, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgAdmin)
+	if !ok {
+		return
+	}
+	if role == OrgOwner && myRole != OrgOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only owners can add owners"})
+		return
+	}
+
+	var userID string
+	if err := h.DB.QueryRow(ctx, `
+		select id::text from users where lower(username) = lower($1)
+	`, username).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		insert into org_members (org_id, user_id, role)
+		values ($1::uuid, $2::uuid, $3)
+		on conflict (org_id, user_id) do nothing
+	`, orgID, userID, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "already a member"})
+		return
+	}
+
+	out, err := loadOrgMember(ctx, h.DB, orgID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// UpdateOrgMember changes a member's role. Promoting to or demoting from
+// owner needs an owner, and the last owner can't be demoted.
+func (h *Handler) UpdateOrgMember(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, userID, ok := parseOrgMemberPath(c)
+	if !ok {
+		return
+	}
+
+	var req updateOrgMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if !isValidOrgRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !lockOrg(c, ctx, tx, orgID) {
+		return
+	}
+	myRole, ok := requireOrgRole(c, ctx, tx, orgID, uid, OrgAdmin)
+	if !ok {
+		return
+	}
+
+	current, err := orgRole(ctx, tx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if (current == OrgOwner || role == OrgOwner) && myRole != OrgOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only owners can change owners"})
+		return
+	}
+	if current == OrgOwner && role != OrgOwner && !requireAnotherOrgOwner(c, ctx, tx, orgID, userID) {
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update org_members set role = $3
+		where org_id::text = $1 and user_id::text = $2
+	`, orgID, userID, role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadOrgMember(ctx, tx, orgID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// RemoveOrgMember lets admins remove members (owners only by owners) and
+// anyone leave, as long as an owner remains. Project memberships are kept.
+func (h *Handler) RemoveOrgMember(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, userID, ok := parseOrgMemberPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !lockOrg(c, ctx, tx, orgID) {
+		return
+	}
+	need := OrgAdmin
+	if userID == uid {
+		need = OrgMember
+	}
+	myRole, ok := requireOrgRole(c, ctx, tx, orgID, uid, need)
+	if !ok {
+		return
+	}
+
+	current, err := orgRole(ctx, tx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if current == OrgOwner {
+		if myRole != OrgOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only owners can remove owners"})
+			return
+		}
+		if !requireAnotherOrgOwner(c, ctx, tx, orgID, userID) {
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from org_members
+		where org_id::text = $1 and user_id::text = $2
+	`, orgID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// ListOrgProjects lists the organization's projects for any org member.
+// Trashed projects are left out; archived ones need ?include_archived=true.
+func (h *Handler) ListOrgProjects(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+	includeArchived := c.Query("include_archived") == "true"
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgMember); !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select
+			p.id::text,
+			p.name,
+			p.key,
+			p.description,
+			p.owner_id::text,
+			u.username,
+			(select count(*) from projects_members m where m.project_id = p.id)::int,
+			p.archived_at is not null,
+			exists (select 1 from projects_members m where m.project_id = p.id and m.user_id::text = $2)
+		from projects p
+		join users u on u.id = p.owner_id
+		where p.org_id::text = $1
+			and p.deleted_at is null
+			and ($3::boolean or p.archived_at is null)
+		order by lower(p.name), p.id
+	`, orgID, uid, includeArchived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByPos[OrgProject])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if out == nil {
+		out = []OrgProject{}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// AddOrgProject moves a project into the organization. The caller must
+// manage the project and belong to the organization, and when the project
+// sits in another organization, be an owner or admin there too. From then
+// on the org's admins manage it.
+func (h *Handler) AddOrgProject(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+
+	var req addOrgProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(req.ProjectID)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgMember); !ok {
+		return
+	}
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the row so the org it belongs to can't change under the checks
+	var currentOrg *string
+	if err := tx.QueryRow(ctx, `
+		select org_id::text from projects
+		where id::text = $1 and deleted_at is null
+		for update
+	`, projectID).Scan(&currentOrg); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !requireProjectManager(c, ctx, tx, projectID, uid) {
+		return
+	}
+
+	// taking a project from another organization is up to that org's admins
+	if currentOrg != nil && *currentOrg != orgID {
+		role, err := orgRole(ctx, tx, *currentOrg, uid)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if role != OrgOwner && role != OrgAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not an admin of the project's organization"})
+			return
+		}
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		update projects set org_id = $2::uuid
+		where id::text = $1 and (org_id is null or org_id::text = $3::text)
+	`, projectID, orgID, currentOrg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "project changed, try again"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "project_id": projectID, "org_id": orgID})
+}
+
+// RemoveOrgProject takes a project out of the organization; it stays with
+// its owner.
+func (h *Handler) RemoveOrgProject(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return
+	}
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := requireOrgRole(c, ctx, h.DB, orgID, uid, OrgAdmin); !ok {
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		update projects set org_id = null
+		where id::text = $1 and org_id::text = $2
+	`, projectID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// managedBy is a SQL condition on the projects row named table: the user in
+// placeholder param (compared as text) owns it, or is an owner or admin of
+// its organization.
+func managedBy(table, param string) string {
+	return `(` + table + `.owner_id::text = ` + param + ` or exists (
+		select 1 from org_members om
+		where om.org_id = ` + table + `.org_id
+			and om.user_id::text = ` + param + `
+			and om.role in ('owner', 'admin')
+	))`
+}
+
+// managesProject reports whether userID may manage the project. It returns
+// pgx.ErrNoRows when the project is missing or in the trash.
+func managesProject(ctx context.Context, q querier, projectID, userID string) (bool, error) {
+	var ok bool
+	err := q.QueryRow(ctx, `
+		select `+managedBy("p", "$2")+`
+		from projects p
+		where p.id::text = $1 and p.deleted_at is null
+	`, projectID, userID).Scan(&ok)
+	return ok, err
+}
+
+// requireProjectManager responds and returns false unless userID manages
+// the project.
+func requireProjectManager(c *gin.Context, ctx context.Context, q querier, projectID, userID string) bool {
+	ok, err := managesProject(ctx, q, projectID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+		return false
+	}
+	return true
+}
+
+func isValidOrgRole(role string) bool {
+	switch role {
+	case OrgOwner, OrgAdmin, OrgMember:
+		return true
+	default:
+		return false
+	}
+}
+
+// orgRoleRank orders org members owners first.
+const orgRoleRank = `case om.role when 'owner' then 1 when 'admin' then 2 else 3 end`
+
+// orgRole returns pgx.ErrNoRows when userID is not in the organization.
+func orgRole(ctx context.Context, q querier, orgID, userID string) (string, error) {
+	var role string
+	err := q.QueryRow(ctx, `
+		select role from org_members
+		where org_id::text = $1 and user_id::text = $2
+	`, orgID, userID).Scan(&role)
+	return role, err
+}
+
+// requireOrgRole responds and returns false unless userID holds at least
+// the role need. Non-members get a 404 so organizations don't leak.
+func requireOrgRole(c *gin.Context, ctx context.Context, q querier, orgID, userID, need string) (string, bool) {
+	role, err := orgRole(ctx, q, orgID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return "", false
+	}
+
+	switch {
+	case need == OrgOwner && role != OrgOwner:
+		c.JSON(http.StatusForbidden, gin.H{"error": "not an organization owner"})
+		return "", false
+	case need == OrgAdmin && role == OrgMember:
+		c.JSON(http.StatusForbidden, gin.H{"error": "not an organization admin"})
+		return "", false
+	}
+	return role, true
+}
+
+// lockOrg serializes role changes so two owners can't demote each other at
+// once and leave the organization without one.
+func lockOrg(c *gin.Context, ctx context.Context, tx pgx.Tx, orgID string) bool {
+	if _, err := tx.Exec(ctx, `
+		select 1 from organizations where id::text = $1 for update
+	`, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	return true
+}
+
+func requireAnotherOrgOwner(c *gin.Context, ctx context.Context, tx pgx.Tx, orgID, userID string) bool {
+	var others bool
+	if err := tx.QueryRow(ctx, `
+		select exists (
+			select 1 from org_members
+			where org_id::text = $1 and user_id::text <> $2 and role = 'owner'
+		)
+	`, orgID, userID).Scan(&others); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if !others {
+		c.JSON(http.StatusConflict, gin.H{"error": "organization needs another owner first"})
+		return false
+	}
+	return true
+}
+
+func parseOrgPath(c *gin.Context) (string, bool) {
+	orgID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("orgId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return "", false
+	}
+	return orgID.String(), true
+}
+
+func parseOrgMemberPath(c *gin.Context) (string, string, bool) {
+	orgID, ok := parseOrgPath(c)
+	if !ok {
+		return "", "", false
+	}
+	userID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("userId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return "", "", false
+	}
+	return orgID, userID.String(), true
+}
+
+// orgColumns lists the columns scanOrg expects, with the caller's
+// membership aliased as me.
+const orgColumns = `
+	o.id::text,
+	o.name,
+	me.role,
+	(select count(*) from org_members m where m.org_id = o.id)::int,
+	(select count(*) from projects p where p.org_id = o.id and p.deleted_at is null)::int,
+	o.created_at
+`
+
+// loadOrg returns pgx.ErrNoRows unless userID is a member.
+func loadOrg(ctx context.Context, q querier, orgID, userID string) (Organization, error) {
+	return scanOrg(q.QueryRow(ctx, `
+		select `+orgColumns+`
+		from org_members me
+		join organizations o on o.id = me.org_id
+		where me.org_id::text = $1 and me.user_id::text = $2
+	`, orgID, userID))
+}
+
+func scanOrg(row pgx.Row) (Organization, error) {
+	var o Organization
+	var createdAt time.Time
+	if err := row.Scan(&o.ID, &o.Name, &o.Role, &o.MemberCount, &o.ProjectCount, &createdAt); err != nil {
+		return Organization{}, err
+	}
+	o.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	return o, nil
+}
+
+const orgMemberColumns = `
+	om.user_id::text,
+	u.username,
+	om.role,
+	om.created_at
+`
+
+func loadOrgMember(ctx context.Context, q querier, orgID, userID string) (OrgMemberInfo, error) {
+	return scanOrgMember(q.QueryRow(ctx, `
+		select `+orgMemberColumns+`
+		from org_members om
+		join users u on u.id = om.user_id
+		where om.org_id::text = $1 and om.user_id::text = $2
+	`, orgID, userID))
+}
+
+func scanOrgMember(row pgx.Row) (OrgMemberInfo, error) {
+	var m OrgMemberInfo
+	var joinedAt time.Time
+	if err := row.Scan(&m.UserID, &m.Username, &m.Role, &joinedAt); err != nil {
+		return OrgMemberInfo{}, err
+	}
+	m.JoinedAt = joinedAt.UTC().Format(time.RFC3339)
+	return m, nil
+}
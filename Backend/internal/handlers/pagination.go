@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// pageLimit reads ?limit=, clamped to [1, maxPageSize].
+func pageLimit(c *gin.Context) (int, bool) {
+	raw := strings.TrimSpace(c.Query("limit"))
+	if raw == "" {
+		return defaultPageSize, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	if n > maxPageSize {
+		n = maxPageSize
+	}
+	return n, true
+}
+
+// Cursors are opaque to clients: base64url-encoded JSON of the last row's
+// sort key.
+func encodeCursor(v any) *string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	s := base64.RawURLEncoding.EncodeToString(b)
+	return &s
+}
+
+func decodeCursor(raw string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxSkillTerms = 10
+
+// skillTermRe matches "Go >= 7", "Go:7" and plain "Go" (any proficiency).
+var skillTermRe = regexp.MustCompile(`^(.+?)\s*(?:(?:>=|:)\s*(\d+))?$`)
+
+// skillAndRe splits terms joined with "and", as in "Go >= 7 and Postgres >= 5".
+var skillAndRe = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// ========= People DTOs (responses) =========
+// PersonHit is a user matching every required skill. Skills lists the
+// matched ones; Score sums their proficiency and orders the results.
+type PersonHit struct {
+	ID       string  `json:"id"`
+	Username string  `json:"username"`
+	Name     *string `json:"name"` // null when hidden from the caller
+	Skills   []Skill `json:"skills"`
+	Score    int     `json:"score"`
+}
+
+type PeoplePage struct {
+	Results    []PersonHit `json:"results"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// skillTerm is one requirement: a skill name and minimum proficiency.
+type skillTerm struct {
+	Name string
+	Min  int
+}
+
+// FindPeople searches users by skill. ?skills= takes terms separated by
+// commas or "and" ("Go >= 7 and Postgres >= 5"); users need all of them.
+// ?exclude_project= leaves out that project's members (the caller must be
+// one) and ?q= narrows by username. Users whose skills are hidden from the
+// caller are never matched.
+func (h *Handler) FindPeople(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	terms, err := parseSkillTerms(c.Query("skills"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var excludeProject *string
+	if raw := strings.TrimSpace(c.Query("exclude_project")); raw != "" {
+		id, err := uuid.Parse(strings.ToLower(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exclude_project"})
+			return
+		}
+		s := id.String()
+		excludeProject = &s
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	// ranked like Search, so the cursor is an offset
+	var after searchCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		if err := decodeCursor(raw, &after); err != nil || after.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	if excludeProject != nil {
+		allowed, err := isProjectMember(ctx, h.DB, *excludeProject, uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+			return
+		}
+	}
+
+	names := make([]string, len(terms))
+	mins := make([]int, len(terms))
+	for i, t := range terms {
+		names[i] = t.Name
+		mins[i] = t.Min
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		with reqs as (
+			select lower(r.name) as name, max(r.min) as min
+			from unnest($2::text[], $3::int[]) as r(name, min)
+			group by lower(r.name)
+		),
+		candidates as (
+			select u.id, u.username, p.name, coalesce(p.visibility, '{}'::jsonb) as vis
+			from users u
+			left join profiles p on p.user_id = u.id
+			where u.id::text <> $1
+				and ($4::text is null or not exists (
+					select 1 from projects_members pm
+					where pm.project_id::text = $4 and pm.user_id = u.id
+				))
+				and ($5 = '' or strpos(lower(u.username), lower($5)) > 0)
+		),
+		-- skill names aren't unique per user ("Go" and "go"), so each
+		-- requirement counts once, with the user's best match
+		hits as (
+			select distinct on (cand.id, r.name) cand.id as user_id, s.id, s.name, s.proficiency
+			from candidates cand
+			join skills s on s.user_id = cand.id
+			join reqs r on r.name = lower(s.name) and s.proficiency >= r.min
+			where `+fieldVisible("cand.vis", "skills", "cand.id", "$1")+`
+			order by cand.id, r.name, s.proficiency desc, s.id
+		)
+		select
+			cand.id::text,
+			cand.username,
+			case when `+fieldVisible("cand.vis", "name", "cand.id", "$1")+` then cand.name end,
+			json_agg(json_build_object(
+				'id', h.id::text,
+				'name', h.name,
+				'proficiency', h.proficiency
+			) order by h.proficiency desc, lower(h.name)),
+			sum(h.proficiency)::int
+		from candidates cand
+		join hits h on h.user_id = cand.id
+		group by cand.id, cand.username, cand.name, cand.vis
+		having count(*) = (select count(*) from reqs)
+		order by sum(h.proficiency) desc, lower(cand.username), cand.id
+		offset $6
+		limit $7
+	`, uid, names, mins, excludeProject, strings.TrimSpace(c.Query("q")), after.Offset, limit+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := PeoplePage{Results: []PersonHit{}}
+	for rows.Next() {
+		var p PersonHit
+		if err := rows.Scan(&p.ID, &p.Username, &p.Name, &p.Skills, &p.Score); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.Results = append(out.Results, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if len(out.Results) > limit {
+		out.Results = out.Results[:limit]
+		out.NextCursor = encodeCursor(searchCursor{Offset: after.Offset + limit})
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// parseSkillTerms reads the ?skills= expression. A skill named twice keeps
+// the higher minimum. Errors are fit for a 400 response.
+func parseSkillTerms(raw string) ([]skillTerm, error) {
+	var terms []skillTerm
+	seen := map[string]int{}
+	for _, part := range strings.Split(raw, ",") {
+		for _, term := range skillAndRe.Split(part, -1) {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+			m := skillTermRe.FindStringSubmatch(term)
+			if m == nil || strings.ContainsAny(m[1], "<>=") {
+				return nil, errors.New("invalid skills")
+			}
+
+			t := skillTerm{Name: strings.TrimSpace(m[1]), Min: 1}
+			if m[2] != "" {
+				n, err := strconv.Atoi(m[2])
+				if err != nil || n < 1 || n > 10 {
+					return nil, errors.New("invalid proficiency")
+				}
+				t.Min = n
+			}
+
+			key := strings.ToLower(t.Name)
+			if i, ok := seen[key]; ok {
+				terms[i].Min = max(terms[i].Min, t.Min)
+				continue
+			}
+			seen[key] = len(terms)
+			terms = append(terms, t)
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, errors.New("missing skills")
+	}
+	if len(terms) > maxSkillTerms {
+		return nil, errors.New("too many skills")
+	}
+	return terms, nil
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Profile field visibility. Teammates are users who share a project or an
+// organization with the profile's owner. Owners always see their own
+// profile in full.
+const (
+	VisibilityPublic    = "public"
+	VisibilityTeammates = "teammates"
+	VisibilityPrivate   = "private"
+)
+
+// ProfileVisibility holds one level per profile field. Empty means public,
+// so profiles saved before visibility existed stay as they were.
+type ProfileVisibility struct {
+	Name       string `json:"name"`
+	Headline   string `json:"headline"`
+	Bio        string `json:"bio"`
+	Skills     string `json:"skills"`
+	Educations string `json:"educations"`
+}
+
+// ========= Public profile DTOs (responses) =========
+// PublicProfile is another user's profile as the caller may see it. Hidden
+// fields are null.
+type PublicProfile struct {
+	Username   string      `json:"username"`
+	Name       *string     `json:"name"`
+	Headline   *string     `json:"headline"`
+	Bio        *string     `json:"bio"`
+	Skills     []Skill     `json:"skills"`
+	Educations []Education `json:"educations"`
+	IsTeammate bool        `json:"is_teammate"`
+}
+
+// GetUserProfile returns the profile of :username, limited by the
+// visibility its owner chose.
+func (h *Handler) GetUserProfile(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	username := strings.TrimSpace(c.Param("username"))
+	if username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing username"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	var userID string
+	var out PublicProfile
+	var name, headline, bio string
+	var vis ProfileVisibility
+	if err := h.DB.QueryRow(ctx, `
+		select u.id::text, u.username, coalesce(p.name, ''), coalesce(p.headline, ''), coalesce(p.bio, ''),
+			coalesce(p.visibility, '{}'::jsonb),
+			`+teammateOf("u.id", "$2")+`
+		from users u
+		left join profiles p on p.user_id = u.id
+		where lower(u.username) = lower($1)
+	`, username, uid).Scan(&userID, &out.Username, &name, &headline, &bio, &vis, &out.IsTeammate); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	self := userID == uid
+	visible := func(level string) bool {
+		switch {
+		case self:
+			return true
+		case level == VisibilityPrivate:
+			return false
+		case level == VisibilityTeammates:
+			return out.IsTeammate
+		default:
+			return true
+		}
+	}
+
+	if visible(vis.Name) {
+		out.Name = &name
+	}
+	if visible(vis.Headline) {
+		out.Headline = &headline
+	}
+	if visible(vis.Bio) {
+		out.Bio = &bio
+	}
+	if visible(vis.Skills) {
+		skills, err := loadSkills(ctx, h.DB, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.Skills = skills
+	}
+	if visible(vis.Educations) {
+		educations, err := loadEducations(ctx, h.DB, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.Educations = educations
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) GetProfileVisibility(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	var vis ProfileVisibility
+	if err := h.DB.QueryRow(ctx, `
+		select visibility from profiles where user_id::text = $1
+	`, uid).Scan(&vis); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, vis.withDefaults())
+}
+
+// UpdateProfileVisibility changes the levels given; omitted fields keep
+// theirs.
+func (h *Handler) UpdateProfileVisibility(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	var req ProfileVisibility
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	// only the fields given are merged into the stored levels
+	patch := map[string]string{}
+	for field, level := range map[string]string{
+		"name":       req.Name,
+		"headline":   req.Headline,
+		"bio":        req.Bio,
+		"skills":     req.Skills,
+		"educations": req.Educations,
+	} {
+		if level == "" {
+			continue
+		}
+		if !isValidVisibility(level) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid visibility"})
+			return
+		}
+		patch[field] = level
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	var vis ProfileVisibility
+	if err := h.DB.QueryRow(ctx, `
+		update profiles
+		set visibility = visibility || $2::jsonb,
+			updated_at = now()
+		where user_id::text = $1
+		returning visibility
+	`, uid, patch).Scan(&vis); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, vis.withDefaults())
+}
+
+func (v ProfileVisibility) withDefaults() ProfileVisibility {
+	for _, level := range []*string{&v.Name, &v.Headline, &v.Bio, &v.Skills, &v.Educations} {
+		if *level == "" {
+			*level = VisibilityPublic
+		}
+	}
+	return v
+}
+
+// teammateOf is a SQL condition: the user whose id is in column col shares
+// a live project or an organization with the user in placeholder param.
+func teammateOf(col, param string) string {
+	return `(exists (
+		select 1
+		from projects_members a
+		join projects_members b on b.project_id = a.project_id
+		join projects pr on pr.id = a.project_id
+		where a.user_id = ` + col + ` and b.user_id::text = ` + param + ` and pr.deleted_at is null
+	) or exists (
+		select 1
+		from org_members a
+		join org_members b on b.org_id = a.org_id
+		where a.user_id = ` + col + ` and b.user_id::text = ` + param + `
+	))`
+}
+
+// fieldVisible is a SQL condition: field of the visibility jsonb vis lets
+// the user in placeholder param see it on the profile of user col.
+func fieldVisible(vis, field, col, param string) string {
+	level := `coalesce(` + vis + `->>'` + field + `', 'public')`
+	return `(` + col + `::text = ` + param + ` or ` + level + ` = 'public' or (` +
+		level + ` = 'teammates' and ` + teammateOf(col, param) + `))`
+}
+
+func isValidVisibility(level string) bool {
+	switch level {
+	case VisibilityPublic, VisibilityTeammates, VisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// loadSkills returns the user's skills, strongest first.
+func loadSkills(ctx context.Context, q querier, userID string) ([]Skill, error) {
+	rows, err := q.Query(ctx, `
+		select id::text, name, proficiency
+		from skills
+		where user_id::text = $1
+		order by proficiency desc, lower(name) asc
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Skill])
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Skill{}
+	}
+	return out, nil
+}
+
+// loadEducations returns the user's educations, most recent first.
+func loadEducations(ctx context.Context, q querier, userID string) ([]Education, error) {
+	rows, err := q.Query(ctx, `
+		select id::text, school, degree, major, start_year, end_year
+		from educations
+		where user_id::text = $1
+		order by start_year desc, lower(school) asc
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Education])
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Education{}
+	}
+	return out, nil
+}
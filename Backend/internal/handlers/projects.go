@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"forge-api/internal/rank"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -20,30 +25,86 @@ type Member struct {
 type Project struct {
 	ID          string   	`json:"id"`
 	Name        string   	`json:"name"`
+	Key         string   	`json:"key"`
 	Description string   	`json:"description"`
 	OwnerId     string   	`json:"owner_id"`
+	OrgID       *string  	`json:"org_id"`
 	CustomRoles []string	`json:"custom_roles"`
 	Members     []Member 	`json:"members"`
 	Tasks       []Task   	`json:"tasks"`
 	IsPinned    bool     	`json:"is_pinned"`
+	IsHidden    bool     	`json:"is_hidden"`
+	IsArchived  bool     	`json:"is_archived"`
 	SortIndex   int      	`json:"sort_index"`
+	Version     int      	`json:"version"`
+	Progress    int      	`json:"progress"`
+	Labels      []Label  	`json:"labels"`
 }
 
 type EditProjectDetail struct {
 	ID          string 	`json:"id"`
 	Name        string 	`json:"name"`
+	Key         string 	`json:"key"`
 	Description string 	`json:"description"`
+	Version     int    	`json:"version"`
+}
+
+// ProjectSummary is a Project without members and tasks, for list views.
+type ProjectSummary struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Key         string     `json:"key"`
+	Description string     `json:"description"`
+	OwnerId     string     `json:"owner_id"`
+	OrgID       *string    `json:"org_id"`
+	CustomRoles []string   `json:"custom_roles"`
+	MemberCount int        `json:"member_count"`
+	TaskCounts  TaskCounts `json:"task_counts"`
+	IsPinned    bool       `json:"is_pinned"`
+	IsHidden    bool       `json:"is_hidden"`
+	IsArchived  bool       `json:"is_archived"`
+	SortIndex   int        `json:"sort_index"`
+}
+
+// TaskCounts are keyed by task status.
+type TaskCounts struct {
+	Backlog    int `json:"backlog"`
+	InProgress int `json:"inProgress"`
+	Blocked    int `json:"blocked"`
+	Done       int `json:"done"`
+	Total      int `json:"total"`
+}
+
+type ProjectSummaryPage struct {
+	Projects   []ProjectSummary `json:"projects"`
+	NextCursor *string          `json:"next_cursor"`
+}
+
+type projectCursor struct {
+	Rank string `json:"r"`
+	ID   string `json:"id"`
+}
+
+// ProjectPreferences are the caller's per-membership display settings.
+type ProjectPreferences struct {
+	ProjectID string `json:"project_id"`
+	IsPinned  bool   `json:"is_pinned"`
+	IsHidden  bool   `json:"is_hidden"`
+	SortIndex int    `json:"sort_index"`
 }
 
 // ========= Requests =========
 type createProjectReq struct {
 	Name        string 	`json:"name"`
+	Key         string 	`json:"key"` // optional; derived from the name when empty
 	Description string 	`json:"description"`
+	OrgID       string 	`json:"org_id"` // optional; the caller must belong to the organization
 }
 
 type editProjectDetailsReq struct {
 	ID          string 	`json:"id"`
 	Name        string 	`json:"name"`
+	Key         string 	`json:"key"` // optional; empty keeps the current key
 	Description string 	`json:"description"`
 }
 
@@ -51,8 +112,9 @@ type reorderProjectsReq struct {
 	ProjectIDs []string `json:"project_ids"`
 }
 
-type addCustomRoles struct {
-	Roles []string `json:"custom_roles"`
+type projectPreferencesReq struct {
+	IsPinned *bool `json:"is_pinned"`
+	IsHidden *bool `json:"is_hidden"`
 }
 
 // Helper function to extract and validate user ID from context
@@ -76,6 +138,10 @@ func (h *Handler) GetProjects(c *gin.Context) {
 		return
 	}
 
+	// Hidden and archived projects are left out unless explicitly asked for
+	includeHidden := c.Query("include_hidden") == "true"
+	includeArchived := c.Query("include_archived") == "true"
+
 	ctx, cancel := contextTimeout(c, 5*time.Second)
 	defer cancel()
 
@@ -86,20 +152,29 @@ func (h *Handler) GetProjects(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
-	rows, err := h.DB.Query(ctx, `
+	// pin/order/hidden are the caller's own preferences, stored per membership
+	rows, err := tx.Query(ctx, `
 		select
 			p.id::text,
 			p.name,
+			p.key,
 			p.description,
 			p.owner_id::text,
+			p.org_id::text,
 			p.custom_roles,
-			p.is_pinned,
-			p.sort_index
+			pm.is_pinned,
+			pm.is_hidden,
+			p.archived_at is not null,
+			`+memberPosition+`,
+			p.version
 		from projects_members pm
 		join projects p on p.id = pm.project_id
 		where pm.user_id = $1
-		order by p.sort_index asc, p.created_at desc, lower(p.name) asc
-	`, userID)
+			and p.deleted_at is null
+			and ($2::boolean or not pm.is_hidden)
+			and ($3::boolean or p.archived_at is null)
+		order by pm.rank asc, pm.id asc
+	`, userID, includeHidden, includeArchived)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
@@ -111,7 +186,7 @@ func (h *Handler) GetProjects(c *gin.Context) {
 
 	for rows.Next() {
 		var p Project
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.OwnerId, &p.CustomRoles, &p.IsPinned, &p.SortIndex); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Key, &p.Description, &p.OwnerId, &p.OrgID, &p.CustomRoles, &p.IsPinned, &p.IsHidden, &p.IsArchived, &p.SortIndex, &p.Version); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 			return
 		}
@@ -132,129 +207,41 @@ func (h *Handler) GetProjects(c *gin.Context) {
 	}
 
 	// 2) Fetch all members for those project IDs
-	// We'll use ANY($1) with text[] and compare to project_id::text
-	memRows, err := h.DB.Query(ctx, `
-		select
-			pm.project_id::text,
-			pm.user_id::text,
-			pm.username,
-			pm.role_key
-		from projects_members pm
-		where pm.project_id::text = any($1)
-		order by lower(pm.username) asc
-	`, projectIDs)
+	memberMap, err := loadProjectMembers(ctx, tx, projectIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
-	defer memRows.Close()
-
-	// Build map projectID -> []members
-	memberMap := make(map[string][]Member, len(projectIDs))
-
-	for memRows.Next() {
-		var pid string
-		var m Member
-		if err := memRows.Scan(&pid, &m.ID, &m.Username, &m.RoleKey); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-			return
-		}
-		memberMap[pid] = append(memberMap[pid], m)
-	}
-
-	if err := memRows.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-		return
-	}
-
-	// Attach members to each project
-	for i := range projects {
-		projects[i].Members = memberMap[projects[i].ID]
-	}
 
 	// 3) Fetch all tasks for those project IDs
-	taskRows, err := h.DB.Query(ctx, `
-		select
-			t.project_id::text,
-			t.id::text,
-			t.title,
-			coalesce(t.details, ''),
-			t.status,
-			coalesce(t.assignee_id::text, ''),
-			coalesce(u.username, ''),
-			t.difficulty,
-			t.sort_index,
-			t.created_at
-		from tasks t
-		left join users u on u.id = t.assignee_id
-		where t.project_id::text = any($1)
-		order by
-			t.project_id::text asc,
-			case t.status
-				when 'backlog' then 1
-				when 'inProgress' then 2
-				when 'blocked' then 3
-				when 'done' then 4
-				else 9
-			end,
-			t.sort_index asc,
-			t.created_at asc
-	`, projectIDs)
+	taskMap, err := loadProjectTasks(ctx, tx, projectIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
-	defer taskRows.Close()
-
-	// projectID -> []Task
-	taskMap := make(map[string][]Task, len(projectIDs))
-
-	for taskRows.Next() {
-		var pid string
-		var t Task
-		var assigneeID string
-		var assigneeUsername string
-		var createdAt time.Time
-
-		if err := taskRows.Scan(
-			&pid,
-			&t.ID,
-			&t.Title,
-			&t.Details,
-			&t.Status,
-			&assigneeID,
-			&assigneeUsername,
-			&t.Difficulty,
-			&t.SortIndex,
-			&createdAt,
-		); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-			return
-		}
-
-		t.ProjectID = pid
-		if assigneeID != "" {
-			t.AssigneeID = &assigneeID
-		}
-		if assigneeUsername != "" {
-			t.AssigneeUsername = &assigneeUsername
-		}
-		t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
-
-		taskMap[pid] = append(taskMap[pid], t)
-	}
 
-	if err := taskRows.Err(); err != nil {
+	// 4) Fetch all labels for those project IDs
+	labelMap, err := loadProjectLabels(ctx, tx, projectIDs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	// Attach tasks to each project
+	// Attach members, tasks and labels to each project
 	for i := range projects {
+		projects[i].Members = memberMap[projects[i].ID]
+		if projects[i].Members == nil {
+			projects[i].Members = []Member{}
+		}
 		projects[i].Tasks = taskMap[projects[i].ID]
 		if projects[i].Tasks == nil {
 			projects[i].Tasks = []Task{}
 		}
+		projects[i].Progress = projectProgress(projects[i].Tasks)
+		projects[i].Labels = labelMap[projects[i].ID]
+		if projects[i].Labels == nil {
+			projects[i].Labels = []Label{}
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -290,6 +277,27 @@ func (h *Handler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	key := deriveProjectKey(name)
+	if strings.TrimSpace(req.Key) != "" {
+		k, ok := normalizeProjectKey(req.Key)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key"})
+			return
+		}
+		key = k
+	}
+
+	var orgID *string
+	if raw := strings.TrimSpace(req.OrgID); raw != "" {
+		id, err := uuid.Parse(strings.ToLower(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid org_id"})
+			return
+		}
+		s := id.String()
+		orgID = &s
+	}
+
 	// description := strings.TrimSpace(req.Description)
 	// if description == "" {
 	// 	c.JSON(http.StatusBadRequest, gin.H{"error": "missing description"})
@@ -306,28 +314,38 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
+	if orgID != nil {
+		if _, ok := requireOrgRole(c, ctx, tx, *orgID, ownerID, OrgMember); !ok {
+			return
+		}
+	}
+
 	var projectID string
-	var sortIndex int
-	if err := h.DB.QueryRow(ctx,
-		`insert into projects (name, description, owner_id, sort_index)
-		values (
-			$1,
-			$2,
-			$3,
-			coalesce((select max(sort_index) + 1 from projects where owner_id = $3), 0)
-			)
-		returning id::text, sort_index
-	`, name, req.Description, ownerID).Scan(&projectID, &sortIndex); err != nil {
+	if err := tx.QueryRow(ctx,
+		`insert into projects (name, key, description, owner_id, org_id)
+		values ($1, $2, $3, $4, $5)
+		returning id::text
+	`, name, key, req.Description, ownerID, orgID).Scan(&projectID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
+	// New projects go to the end of the owner's list
+	memberRank, err := memberRankFor(ctx, tx, ownerID, projectID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	// the count in returning doesn't see the new row, so it is its position
 	var members Member
-	if err := h.DB.QueryRow(ctx,
-		`insert into projects_members (project_id, user_id, username, role_key)
-		values ($1, $2, $3, $4)
-		returning user_id::text, username, role_key
-	`, projectID, ownerID, usr, "frontend").Scan(&members.ID, &members.Username, &members.RoleKey); err != nil {
+	var sortIndex int
+	if err := tx.QueryRow(ctx,
+		`insert into projects_members (project_id, user_id, username, role_key, rank)
+		values ($1, $2, $3, $4, $5)
+		returning user_id::text, username, role_key,
+			(select count(*) from projects_members where user_id = $2)::int
+	`, projectID, ownerID, usr, "frontend", memberRank).Scan(&members.ID, &members.Username, &members.RoleKey, &sortIndex); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
@@ -340,12 +358,17 @@ func (h *Handler) CreateProject(c *gin.Context) {
 	c.JSON(http.StatusOK, Project{
 		ID:          projectID,
 		Name:        name,
+		Key:         key,
 		Description: req.Description,
 		OwnerId:     ownerID,
+		OrgID:       orgID,
+		CustomRoles: []string{},
 		Members:     []Member{members},
 		Tasks:       []Task{},
+		Labels:      []Label{},
 		IsPinned:    false,
 		SortIndex:   sortIndex,
+		Version:     1,
 	})
 }
 
@@ -373,8 +396,24 @@ func (h *Handler) EditProjectDetails(c *gin.Context) {
 		return
 	}
 
+	// Renaming the key changes every task's key; old keys stop resolving
+	var key *string
+	if strings.TrimSpace(req.Key) != "" {
+		k, ok := normalizeProjectKey(req.Key)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key"})
+			return
+		}
+		key = &k
+	}
+
 	description := strings.TrimSpace(req.Description)
 
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := contextTimeout(c, 5*time.Second)
 	defer cancel()
 
@@ -386,15 +425,25 @@ func (h *Handler) EditProjectDetails(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
+	if !requireWritableProject(c, ctx, tx, id) {
+		return
+	}
+
+	if !checkProjectVersion(c, ctx, tx, id, ownerID, expected) {
+		return
+	}
+
 	var updated EditProjectDetail
-	if err := h.DB.QueryRow(ctx,
+	if err := tx.QueryRow(ctx,
 		`update projects 
 		set name = $1, 
-		description = $2
+		description = $2,
+		key = coalesce($5, key),
+		version = version + 1
 		where id = $3::uuid 
-		and owner_id = $4
-		returning id::text, name, description
-	`, name, description, id, ownerID).Scan(&updated.ID, &updated.Name, &updated.Description); err != nil {
+		and `+managedBy("projects", "$4")+`
+		returning id::text, name, key, description, version
+	`, name, description, id, ownerID, key).Scan(&updated.ID, &updated.Name, &updated.Key, &updated.Description, &updated.Version); err != nil {
 		if err == pgx.ErrNoRows {
 			fmt.Print(err)
 			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
@@ -404,9 +453,41 @@ func (h *Handler) EditProjectDetails(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	setETag(c, updated.Version)
 	c.JSON(http.StatusOK, updated)
 }
 
+// checkProjectVersion locks the project row if the caller manages it and,
+// when the client sent If-Match, answers 412 with the current details on a
+// mismatch.
+func checkProjectVersion(c *gin.Context, ctx context.Context, tx pgx.Tx, id, ownerID string, expected *int) bool {
+	var current EditProjectDetail
+	if err := tx.QueryRow(ctx, `
+		select id::text, name, key, description, version
+		from projects
+		where id = $1::uuid and `+managedBy("projects", "$2")+` and deleted_at is null
+		for update
+	`, id, ownerID).Scan(&current.ID, &current.Name, &current.Key, &current.Description, &current.Version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+
+	if expected != nil && *expected != current.Version {
+		versionConflict(c, current.Version, current)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) DeleteProject(c *gin.Context) {
 	ownerID, ok := getAuthUID(c)
 	if !ok {
@@ -419,6 +500,11 @@ func (h *Handler) DeleteProject(c *gin.Context) {
 		return
 	}
 
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := contextTimeout(c, 5*time.Second)
 	defer cancel()
 
@@ -429,9 +515,16 @@ func (h *Handler) DeleteProject(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
-	cmd, err := h.DB.Exec(ctx,
-		`delete from projects 
-		where id = $1::uuid and owner_id = $2
+	if !checkProjectVersion(c, ctx, tx, id, ownerID, expected) {
+		return
+	}
+
+	// Soft delete: the project moves to the owner's trash and can be restored
+	// until the purge job removes it after TrashRetention.
+	cmd, err := tx.Exec(ctx,
+		`update projects
+		set deleted_at = now()
+		where id = $1::uuid and `+managedBy("projects", "$2")+` and deleted_at is null
 	`, id, ownerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
@@ -443,6 +536,16 @@ func (h *Handler) DeleteProject(c *gin.Context) {
 		return
 	}
 
+	if err := stopProjectTimers(ctx, tx, id, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
@@ -479,9 +582,9 @@ func (h *Handler) PinProject(c *gin.Context) {
 	defer tx.Rollback(ctx)
 
 	cmd, err := h.DB.Exec(ctx,
-		`update projects 
+		`update projects_members
 		set is_pinned = $1::boolean
-		where id = $2::uuid and owner_id = $3
+		where project_id = $2::uuid and user_id = $3
 	`, pin, id, ownerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
@@ -539,13 +642,13 @@ func (h *Handler) ReorderProjects(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
-	// Ensure all provided projects belong to this owner
+	// Ensure the caller is a member of every provided project
 	var count int
 	if err := tx.QueryRow(ctx,
-		`select count(*) 
-		from projects 
-		where owner_id = $1::uuid 
-			and id::text = any($2)
+		`select count(*)
+		from projects_members
+		where user_id = $1::uuid
+			and project_id::text = any($2)
 		`, ownerID, ids,
 	).Scan(&count); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
@@ -557,17 +660,17 @@ func (h *Handler) ReorderProjects(c *gin.Context) {
 		return
 	}
 
-	// Update sort_index based on the provided order
+	// Give the provided order fresh, evenly spaced ranks
 	cmd, err := tx.Exec(ctx, `
-        with ord(pid, ord) as (
-			select * from unnest($1::uuid[]) with ordinality
+        with ord(pid, rank) as (
+			select * from unnest($1::uuid[], $3::text[])
         )
-        update projects p
-        set sort_index = (ord.ord - 1)
+        update projects_members pm
+        set rank = ord.rank
         from ord
-        where p.id = ord.pid
-			and p.owner_id = $2::uuid
-    `, ids, ownerID)
+        where pm.project_id = ord.pid
+			and pm.user_id = $2::uuid
+    `, ids, ownerID, rank.Spread(len(ids)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
@@ -586,31 +689,65 @@ func (h *Handler) ReorderProjects(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
-func (h *Handler) AddCustomRoles(c *gin.Context) {
-	_, ok := getAuthUID(c)
+// UpdateProjectPreferences sets the caller's own pin/hidden flags for a
+// project. Omitted fields are left unchanged.
+func (h *Handler) UpdateProjectPreferences(c *gin.Context) {
+	userID, ok := getAuthUID(c)
 	if !ok {
 		return
 	}
 
-	id := strings.TrimSpace(c.Param("id"))
+	id := strings.TrimSpace(c.Param("projectId"))
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
 		return
 	}
 
-	ctx, cancel := contextTimeout(c, 8*time.Second)
-	defer cancel()
-
-	var req addCustomRoles
+	var req projectPreferencesReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
 		return
 	}
 
-	if len(req.Roles) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing custom roles"})
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	var out ProjectPreferences
+	if err := h.DB.QueryRow(ctx,
+		`update projects_members pm
+		set is_pinned = coalesce($1, is_pinned),
+			is_hidden = coalesce($2, is_hidden)
+		where project_id = $3::uuid and user_id = $4
+		returning project_id::text, is_pinned, is_hidden, `+memberPosition+`
+	`, req.IsPinned, req.IsHidden, id, userID).Scan(&out.ProjectID, &out.IsPinned, &out.IsHidden, &out.SortIndex); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetProject returns a single project (with members and tasks) the caller
+// belongs to.
+func (h *Handler) GetProject(c *gin.Context) {
+	userID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	id := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
 	}
 
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
 	tx, err := h.DB.Begin(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
@@ -618,36 +755,268 @@ func (h *Handler) AddCustomRoles(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
-	var existingCustomRoles []string
-	if err := tx.QueryRow(ctx, `
-		select custom_roles 
-		from projects
-			where id = $1::uuid
-	`, id).Scan(&existingCustomRoles); err != nil {
+	project, err := loadProject(ctx, tx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	var combinedCustomRoles = append(existingCustomRoles, req.Roles...)
-	cmd, err := tx.Exec(ctx, `
-		update projects
-		set custom_roles = $1
-		where id = $2::uuid
-	`, combinedCustomRoles, id)
-	if err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	if int(cmd.RowsAffected()) == 0 {
+	setETag(c, project.Version)
+	c.JSON(http.StatusOK, project)
+}
+
+// ListProjectSummaries is the lightweight, paginated alternative to
+// GetProjects: per-status task counts instead of the tasks themselves.
+func (h *Handler) ListProjectSummaries(c *gin.Context) {
+	userID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	var after *projectCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		after = &projectCursor{}
+		err := decodeCursor(raw, after)
+		if err == nil {
+			_, err = uuid.Parse(after.ID) // compared as ::uuid below
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	var afterRank *string
+	var afterID *string
+	if after != nil {
+		afterRank = &after.Rank
+		afterID = &after.ID
+	}
+
+	includeHidden := c.Query("include_hidden") == "true"
+	includeArchived := c.Query("include_archived") == "true"
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select
+			p.id::text,
+			p.name,
+			p.key,
+			p.description,
+			p.owner_id::text,
+			p.org_id::text,
+			p.custom_roles,
+			pm.is_pinned,
+			pm.is_hidden,
+			p.archived_at is not null,
+			`+memberPosition+`,
+			pm.rank,
+			(select count(*) from projects_members m where m.project_id = p.id),
+			tc.backlog,
+			tc.in_progress,
+			tc.blocked,
+			tc.done,
+			tc.total
+		from projects_members pm
+		join projects p on p.id = pm.project_id
+		left join lateral (
+			select
+				count(*) filter (where t.status = 'backlog') as backlog,
+				count(*) filter (where t.status = 'inProgress') as in_progress,
+				count(*) filter (where t.status = 'blocked') as blocked,
+				count(*) filter (where t.status = 'done') as done,
+				count(*) as total
+			from tasks t
+			where t.project_id = p.id
+		) tc on true
+		where pm.user_id = $1
+			and p.deleted_at is null
+			and ($2::boolean or not pm.is_hidden)
+			and ($3::boolean or p.archived_at is null)
+			and ($4::text is null or (pm.rank, pm.id) > ($4::text, $5::uuid))
+		order by pm.rank asc, pm.id asc
+		limit $6
+	`, userID, includeHidden, includeArchived, afterRank, afterID, limit+1)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(ctx); err != nil {
+	out := ProjectSummaryPage{Projects: []ProjectSummary{}}
+	var lastRank string
+	for rows.Next() {
+		var p ProjectSummary
+		var pRank string
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Key,
+			&p.Description,
+			&p.OwnerId,
+			&p.OrgID,
+			&p.CustomRoles,
+			&p.IsPinned,
+			&p.IsHidden,
+			&p.IsArchived,
+			&p.SortIndex,
+			&pRank,
+			&p.MemberCount,
+			&p.TaskCounts.Backlog,
+			&p.TaskCounts.InProgress,
+			&p.TaskCounts.Blocked,
+			&p.TaskCounts.Done,
+			&p.TaskCounts.Total,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if len(out.Projects) < limit {
+			lastRank = pRank
+		}
+		out.Projects = append(out.Projects, p)
+	}
+	if err := rows.Err(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	if len(out.Projects) > limit {
+		out.Projects = out.Projects[:limit]
+		last := out.Projects[limit-1]
+		out.NextCursor = encodeCursor(projectCursor{Rank: lastRank, ID: last.ID})
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// loadProject returns the project as seen by userID (their pin/order/hidden
+// preferences). pgx.ErrNoRows means the project is missing, trashed or the
+// user is not a member.
+func loadProject(ctx context.Context, q querier, projectID, userID string) (Project, error) {
+	var p Project
+	if err := q.QueryRow(ctx, `
+		select
+			p.id::text,
+			p.name,
+			p.key,
+			p.description,
+			p.owner_id::text,
+			p.org_id::text,
+			p.custom_roles,
+			pm.is_pinned,
+			pm.is_hidden,
+			p.archived_at is not null,
+			`+memberPosition+`,
+			p.version
+		from projects p
+		join projects_members pm on pm.project_id = p.id and pm.user_id::text = $2
+		where p.id::text = $1
+			and p.deleted_at is null
+	`, projectID, userID).Scan(&p.ID, &p.Name, &p.Key, &p.Description, &p.OwnerId, &p.OrgID, &p.CustomRoles, &p.IsPinned, &p.IsHidden, &p.IsArchived, &p.SortIndex, &p.Version); err != nil {
+		return Project{}, err
+	}
+
+	members, err := loadProjectMembers(ctx, q, []string{p.ID})
+	if err != nil {
+		return Project{}, err
+	}
+	tasks, err := loadProjectTasks(ctx, q, []string{p.ID})
+	if err != nil {
+		return Project{}, err
+	}
+
+	p.Members = members[p.ID]
+	if p.Members == nil {
+		p.Members = []Member{}
+	}
+	p.Tasks = tasks[p.ID]
+	if p.Tasks == nil {
+		p.Tasks = []Task{}
+	}
+	p.Progress = projectProgress(p.Tasks)
+
+	labels, err := loadProjectLabels(ctx, q, []string{p.ID})
+	if err != nil {
+		return Project{}, err
+	}
+	p.Labels = labels[p.ID]
+	if p.Labels == nil {
+		p.Labels = []Label{}
+	}
+	return p, nil
+}
+
+// loadProjectMembers returns projectID -> members, ordered by username.
+func loadProjectMembers(ctx context.Context, q querier, projectIDs []string) (map[string][]Member, error) {
+	rows, err := q.Query(ctx, `
+		select
+			pm.project_id::text,
+			pm.user_id::text,
+			pm.username,
+			pm.role_key
+		from projects_members pm
+		where pm.project_id::text = any($1)
+		order by lower(pm.username) asc
+	`, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Member, len(projectIDs))
+	for rows.Next() {
+		var pid string
+		var m Member
+		if err := rows.Scan(&pid, &m.ID, &m.Username, &m.RoleKey); err != nil {
+			return nil, err
+		}
+		out[pid] = append(out[pid], m)
+	}
+	return out, rows.Err()
+}
+
+// loadProjectTasks returns projectID -> tasks in board order.
+func loadProjectTasks(ctx context.Context, q querier, projectIDs []string) (map[string][]Task, error) {
+	rows, err := q.Query(ctx, `
+		select `+taskColumns+`
+		from tasks t
+		where t.project_id::text = any($1)
+		order by
+			t.project_id::text asc,
+			`+taskStatusRank+`,
+			t.rank asc,
+			t.id asc
+	`, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Task, len(projectIDs))
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[t.ProjectID] = append(out[t.ProjectID], t)
+	}
+	return out, rows.Err()
 }
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultBurndownDays   = 30
+	maxBurndownDays       = 366
+	defaultVelocityWeeks  = 12
+	maxVelocityWeeks      = 104
+	defaultVelocityWindow = 4
+)
+
+// ========= Report DTOs (responses) =========
+type BurndownReport struct {
+	ProjectID string          `json:"project_id"`
+	SprintID  *string         `json:"sprint_id"`
+	From      string          `json:"from"` // YYYY-MM-DD, UTC days
+	To        string          `json:"to"`
+	Points    []BurndownPoint `json:"points"`
+}
+
+// BurndownPoint is the state at the end of one day. Points are task
+// difficulty; scope is everything not deleted, remaining is what isn't done.
+type BurndownPoint struct {
+	Date            string   `json:"date"`
+	RemainingPoints int      `json:"remaining_points"`
+	RemainingTasks  int      `json:"remaining_tasks"`
+	ScopePoints     int      `json:"scope_points"`
+	Ideal           *float64 `json:"ideal"` // sprint burndowns only: a straight line to zero
+}
+
+type VelocityReport struct {
+	ProjectID string         `json:"project_id"`
+	Window    int            `json:"window"` // weeks in the rolling average
+	Weeks     []VelocityWeek `json:"weeks"`
+}
+
+// VelocityWeek counts tasks that moved to done during the week starting on
+// WeekStart (a Monday, UTC).
+type VelocityWeek struct {
+	WeekStart      string  `json:"week_start"`
+	CompletedTasks int     `json:"completed_tasks"`
+	Points         int     `json:"points"`
+	RollingAverage float64 `json:"rolling_average"`
+}
+
+// GetBurndown returns remaining difficulty points per day, replayed from task
+// history. ?sprint= limits it to tasks in that sprint on each day and
+// defaults the range to the sprint's dates; otherwise ?from= and ?to=
+// (YYYY-MM-DD) pick the range, by default the last 30 days. Days after today
+// are left out. ?format=csv returns CSV.
+func (h *Handler) GetBurndown(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var sprintID *string
+	if raw := strings.TrimSpace(c.Query("sprint")); raw != "" {
+		id, err := uuid.Parse(strings.ToLower(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint"})
+			return
+		}
+		s := id.String()
+		sprintID = &s
+	}
+
+	var from, to *time.Time
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := strings.TrimSpace(c.Query(p.key))
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.key})
+			return
+		}
+		*p.dst = &t
+	}
+
+	ctx, cancel := contextTimeout(c, 10*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if sprintID != nil {
+		sprint, err := loadSprint(ctx, h.DB, projectID, *sprintID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "sprint not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if from == nil {
+			t, _ := time.Parse(time.DateOnly, sprint.StartDate)
+			from = &t
+		}
+		if to == nil {
+			t, _ := time.Parse(time.DateOnly, sprint.EndDate)
+			to = &t
+		}
+	}
+	if to == nil {
+		to = &today
+	}
+	if from == nil {
+		t := to.AddDate(0, 0, -(defaultBurndownDays - 1))
+		from = &t
+	}
+	if to.Before(*from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to is before from"})
+		return
+	}
+	if to.Sub(*from) >= maxBurndownDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "range too long"})
+		return
+	}
+
+	out := BurndownReport{
+		ProjectID: projectID,
+		SprintID:  sprintID,
+		From:      from.Format(time.DateOnly),
+		To:        to.Format(time.DateOnly),
+		Points:    []BurndownPoint{},
+	}
+
+	last := *to
+	if last.After(today) {
+		last = today
+	}
+
+	if !last.Before(*from) {
+		// Each day sees every task's latest history row up to its end
+		rows, err := h.DB.Query(ctx, `
+			select
+				days.day::date,
+				coalesce(sum(s.difficulty) filter (where s.status <> 'done'), 0)::int,
+				count(s.task_id) filter (where s.status <> 'done')::int,
+				coalesce(sum(s.difficulty), 0)::int
+			from generate_series($2::date::timestamp, $3::date::timestamp, interval '1 day') as days(day)
+			left join lateral (
+				select *
+				from (
+					select distinct on (h.task_id) h.task_id, h.status, h.difficulty, h.sprint_id, h.deleted
+					from task_history h
+					where h.project_id::text = $1
+						and h.recorded_at < (days.day::date + 1)::timestamp at time zone 'UTC'
+					order by h.task_id, h.recorded_at desc, h.id desc
+				) latest
+				where not latest.deleted
+					and ($4::text is null or latest.sprint_id::text = $4)
+			) s on true
+			group by days.day
+			order by days.day
+		`, projectID, *from, last, sprintID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var day time.Time
+			var p BurndownPoint
+			if err := rows.Scan(&day, &p.RemainingPoints, &p.RemainingTasks, &p.ScopePoints); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+			p.Date = day.Format(time.DateOnly)
+			out.Points = append(out.Points, p)
+		}
+		if err := rows.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+	}
+
+	// The ideal line runs from the first day's remaining points to zero on
+	// the last day of the range
+	if sprintID != nil && len(out.Points) > 0 {
+		start := float64(out.Points[0].RemainingPoints)
+		span := to.Sub(*from).Hours() / 24
+		for i := range out.Points {
+			v := start
+			if span > 0 {
+				v = start * (1 - float64(i)/span)
+			}
+			v = float64(int(v*100+0.5)) / 100
+			out.Points[i].Ideal = &v
+		}
+	}
+
+	if wantsCSV(c) {
+		records := [][]string{{"date", "remaining_points", "remaining_tasks", "scope_points", "ideal"}}
+		for _, p := range out.Points {
+			ideal := ""
+			if p.Ideal != nil {
+				ideal = strconv.FormatFloat(*p.Ideal, 'f', 2, 64)
+			}
+			records = append(records, []string{
+				p.Date,
+				strconv.Itoa(p.RemainingPoints),
+				strconv.Itoa(p.RemainingTasks),
+				strconv.Itoa(p.ScopePoints),
+				ideal,
+			})
+		}
+		writeCSV(c, "burndown.csv", records)
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetVelocity returns completed difficulty points per week for the last
+// ?weeks= weeks (default 12, current week included) with a rolling average
+// over ?window= weeks (default 4). A task counts in the week it moved to
+// done; reopened and finished again, it counts again. ?format=csv returns
+// CSV.
+func (h *Handler) GetVelocity(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	weeks, ok := intQuery(c, "weeks", defaultVelocityWeeks, maxVelocityWeeks)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid weeks"})
+		return
+	}
+	window, ok := intQuery(c, "window", defaultVelocityWindow, maxVelocityWeeks)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 10*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	// Rolling averages reach back window-1 weeks before the first one shown
+	rows, err := h.DB.Query(ctx, `
+		with weeks as (
+			select wk
+			from generate_series(
+				date_trunc('week', now() at time zone 'UTC') - ($2::int - 1) * interval '1 week',
+				date_trunc('week', now() at time zone 'UTC'),
+				interval '1 week'
+			) as wk
+		),
+		changes as (
+			select h.recorded_at at time zone 'UTC' as at, h.status, h.difficulty, h.deleted,
+				lag(h.status) over (partition by h.task_id order by h.recorded_at, h.id) as prev_status
+			from task_history h
+			where h.project_id::text = $1
+		),
+		done as (
+			select at, difficulty
+			from changes
+			where status = 'done' and not deleted and prev_status is distinct from 'done'
+		)
+		select
+			weeks.wk,
+			count(done.at)::int,
+			coalesce(sum(done.difficulty), 0)::int
+		from weeks
+		left join done on done.at >= weeks.wk and done.at < weeks.wk + interval '1 week'
+		group by weeks.wk
+		order by weeks.wk
+	`, projectID, weeks+window-1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	var all []VelocityWeek
+	for rows.Next() {
+		var wk time.Time
+		var w VelocityWeek
+		if err := rows.Scan(&wk, &w.CompletedTasks, &w.Points); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		w.WeekStart = wk.Format(time.DateOnly)
+		all = append(all, w)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	sum := 0
+	for i := range all {
+		sum += all[i].Points
+		if i >= window {
+			sum -= all[i-window].Points
+		}
+		n := min(i+1, window)
+		all[i].RollingAverage = float64(int(float64(sum)/float64(n)*100+0.5)) / 100
+	}
+
+	out := VelocityReport{ProjectID: projectID, Window: window, Weeks: []VelocityWeek{}}
+	if len(all) > weeks {
+		all = all[len(all)-weeks:]
+	}
+	out.Weeks = append(out.Weeks, all...)
+
+	if wantsCSV(c) {
+		records := [][]string{{"week_start", "completed_tasks", "points", "rolling_average"}}
+		for _, w := range out.Weeks {
+			records = append(records, []string{
+				w.WeekStart,
+				strconv.Itoa(w.CompletedTasks),
+				strconv.Itoa(w.Points),
+				strconv.FormatFloat(w.RollingAverage, 'f', 2, 64),
+			})
+		}
+		writeCSV(c, "velocity.csv", records)
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// intQuery reads a positive integer query param, def when absent.
+func intQuery(c *gin.Context, key string, def, limit int) (int, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > limit {
+		return 0, false
+	}
+	return n, true
+}
+
+// wantsCSV is true for ?format=csv or an Accept header asking for text/csv.
+func wantsCSV(c *gin.Context) bool {
+	if f := c.Query("format"); f != "" {
+		return f == "csv"
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+func writeCSV(c *gin.Context, filename string, records [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.WriteAll(records)
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Built-in role keys known to every client. Custom roles may not shadow them.
+var builtinRoleKeys = []string{"frontend", "backend", "fullstack", "pm", "qa"}
+
+// ========= Custom Role DTOs (responses) =========
+type CustomRoles struct {
+	ProjectID   string   `json:"project_id"`
+	CustomRoles []string `json:"custom_roles"`
+}
+
+// ========= Requests =========
+type addCustomRolesReq struct {
+	Roles []string `json:"custom_roles"`
+}
+
+type renameCustomRoleReq struct {
+	Name string `json:"name"`
+}
+
+func isBuiltinRole(role string) bool {
+	for _, r := range builtinRoleKeys {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// findRole returns the stored spelling of role (case-insensitive match).
+func findRole(roles []string, role string) (string, bool) {
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+func sortRoles(roles []string) {
+	sort.Slice(roles, func(i, j int) bool {
+		return strings.ToLower(roles[i]) < strings.ToLower(roles[j])
+	})
+}
+
+func (h *Handler) ListCustomRoles(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	out := CustomRoles{ProjectID: projectID}
+	if err := h.DB.QueryRow(ctx, `
+		select custom_roles from projects where id::text = $1
+	`, projectID).Scan(&out.CustomRoles); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	sortRoles(out.CustomRoles)
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) AddCustomRoles(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	var req addCustomRolesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if len(req.Roles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing custom roles"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the project row so concurrent adds can't lose each other's roles
+	var manages bool
+	var roles []string
+	var archived bool
+	if err := tx.QueryRow(ctx, `
+		select `+managedBy("projects", "$2")+`, custom_roles, archived_at is not null
+		from projects
+		where id::text = $1 and deleted_at is null
+		for update
+	`, projectID, uid).Scan(&manages, &roles, &archived); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if !manages {
+		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+		return
+	}
+	if archived {
+		c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		return
+	}
+
+	// Dedupe against existing roles, built-in roles and the request itself.
+	for _, raw := range req.Roles {
+		role := strings.TrimSpace(raw)
+		if role == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid custom role"})
+			return
+		}
+		if isBuiltinRole(role) {
+			continue
+		}
+		if _, exists := findRole(roles, role); exists {
+			continue
+		}
+		roles = append(roles, role)
+	}
+	sortRoles(roles)
+
+	if _, err := tx.Exec(ctx, `
+		update projects
+		set custom_roles = $1, version = version + 1
+		where id::text = $2
+	`, roles, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CustomRoles{ProjectID: projectID, CustomRoles: roles})
+}
+
+func (h *Handler) RenameCustomRole(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	oldRole := strings.TrimSpace(c.Param("role"))
+	if projectID == "" || oldRole == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id or role"})
+		return
+	}
+
+	var req renameCustomRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	newRole := strings.TrimSpace(req.Name)
+	if newRole == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+	if isBuiltinRole(newRole) {
+		c.JSON(http.StatusConflict, gin.H{"error": "role already exists"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var manages bool
+	var roles []string
+	var archived bool
+	if err := tx.QueryRow(ctx, `
+		select `+managedBy("projects", "$2")+`, custom_roles, archived_at is not null
+		from projects
+		where id::text = $1 and deleted_at is null
+		for update
+	`, projectID, uid).Scan(&manages, &roles, &archived); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if !manages {
+		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+		return
+	}
+	if archived {
+		c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		return
+	}
+
+	stored, exists := findRole(roles, oldRole)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		return
+	}
+	// Allow re-casing a role ("Designer" -> "designer"), but not merging two roles.
+	if other, taken := findRole(roles, newRole); taken && other != stored {
+		c.JSON(http.StatusConflict, gin.H{"error": "role already exists"})
+		return
+	}
+
+	for i := range roles {
+		if roles[i] == stored {
+			roles[i] = newRole
+		}
+	}
+	sortRoles(roles)
+
+	if _, err := tx.Exec(ctx, `
+		update projects
+		set custom_roles = $1, version = version + 1
+		where id::text = $2
+	`, roles, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	// Cascade to everyone holding (or invited with) the old role.
+	if _, err := tx.Exec(ctx, `
+		update projects_members
+		set role_key = $1
+		where project_id::text = $2 and role_key = $3
+	`, newRole, projectID, stored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update project_invites
+		set role_key = $1
+		where project_id::text = $2 and role_key = $3
+	`, newRole, projectID, stored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CustomRoles{ProjectID: projectID, CustomRoles: roles})
+}
+
+// DeleteCustomRole removes a custom role. If members or pending invites still
+// use it the request is refused with 409, unless ?reassign_to=<role> names a
+// built-in or remaining custom role to move them to.
+func (h *Handler) DeleteCustomRole(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	role := strings.TrimSpace(c.Param("role"))
+	if projectID == "" || role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id or role"})
+		return
+	}
+
+	reassignTo := strings.TrimSpace(c.Query("reassign_to"))
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var manages bool
+	var roles []string
+	var archived bool
+	if err := tx.QueryRow(ctx, `
+		select `+managedBy("projects", "$2")+`, custom_roles, archived_at is not null
+		from projects
+		where id::text = $1 and deleted_at is null
+		for update
+	`, projectID, uid).Scan(&manages, &roles, &archived); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if !manages {
+		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+		return
+	}
+	if archived {
+		c.JSON(http.StatusConflict, gin.H{"error": "project is archived"})
+		return
+	}
+
+	stored, exists := findRole(roles, role)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		return
+	}
+
+	remaining := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r != stored {
+			remaining = append(remaining, r)
+		}
+	}
+
+	var inUse int
+	if err := tx.QueryRow(ctx, `
+		select
+			(select count(*) from projects_members where project_id::text = $1 and role_key = $2) +
+			(select count(*) from project_invites where project_id::text = $1 and role_key = $2 and status = 'pending')
+	`, projectID, stored).Scan(&inUse); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if inUse > 0 {
+		if reassignTo == "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "role in use", "count": inUse})
+			return
+		}
+
+		target := strings.ToLower(reassignTo)
+		if !isBuiltinRole(reassignTo) {
+			var found bool
+			if target, found = findRole(remaining, reassignTo); !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to"})
+				return
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			update projects_members
+			set role_key = $1
+			where project_id::text = $2 and role_key = $3
+		`, target, projectID, stored); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+
+		if _, err := tx.Exec(ctx, `
+			update project_invites
+			set role_key = $1
+			where project_id::text = $2 and role_key = $3
+		`, target, projectID, stored); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update projects
+		set custom_roles = $1, version = version + 1
+		where id::text = $2
+	`, remaining, projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CustomRoles{ProjectID: projectID, CustomRoles: remaining})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Postgres wraps matches in these while building headlines; they are
+// swapped for <mark> tags after the rest of the text is HTML-escaped, so
+// snippets are safe to render as HTML.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+const headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop +
+	`, MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" … "`
+
+// ========= Search DTOs (responses) =========
+type SearchHit struct {
+	Type        string  `json:"type"` // project | task
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	ProjectName string  `json:"project_name"`
+	Title       string  `json:"title"`
+	Highlight   string  `json:"highlight"` // title with matches in <mark>
+	Snippet     string  `json:"snippet"`   // best fragments of the body, same markup
+	Rank        float32 `json:"rank"`
+}
+
+type SearchPage struct {
+	Results    []SearchHit `json:"results"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// ========= Requests =========
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+// Search runs a full-text query (web-search syntax: quotes, OR, -word) over
+// the names and descriptions of the caller's projects and the titles and
+// details of their tasks. ?type=project|task narrows it; results are
+// ranked, best first.
+func (h *Handler) Search(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing q"})
+		return
+	}
+
+	kind := strings.TrimSpace(c.Query("type"))
+	if kind != "" && kind != "project" && kind != "task" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	// Ranked results have no stable key to seek from, so the cursor is an
+	// offset into the ranking.
+	var after searchCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		if err := decodeCursor(raw, &after); err != nil || after.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	// Headlines are expensive, so they are only built for the page of hits
+	rows, err := h.DB.Query(ctx, `
+		with query as (
+			select websearch_to_tsquery('english', $2) as tsq
+		),
+		mine as (
+			select p.id, p.name, p.description, p.search_tsv
+			from projects p
+			join projects_members pm on pm.project_id = p.id and pm.user_id::text = $1
+			where p.deleted_at is null
+		),
+		hits as (
+			select 'project' as type, m.id, m.id as project_id, m.name as project_name,
+				m.name as title, m.description as body,
+				ts_rank(m.search_tsv, query.tsq) as rank
+			from mine m, query
+			where ($3 = '' or $3 = 'project') and m.search_tsv @@ query.tsq
+			union all
+			select 'task', t.id, m.id, m.name,
+				t.title, t.details,
+				ts_rank(t.search_tsv, query.tsq)
+			from tasks t
+			join mine m on m.id = t.project_id
+			cross join query
+			where ($3 = '' or $3 = 'task') and t.search_tsv @@ query.tsq
+		),
+		page as (
+			select * from hits
+			order by rank desc, type asc, id asc
+			offset $4
+			limit $5
+		)
+		select
+			page.type,
+			page.id::text,
+			page.project_id::text,
+			page.project_name,
+			page.title,
+			ts_headline('english', page.title, query.tsq, 'HighlightAll=true, StartSel=`+headlineStart+`, StopSel=`+headlineStop+`'),
+			ts_headline('english', page.body, query.tsq, '`+headlineOptions+`'),
+			page.rank
+		from page, query
+		order by page.rank desc, page.type asc, page.id asc
+	`, uid, q, kind, after.Offset, limit+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := SearchPage{Results: []SearchHit{}}
+	for rows.Next() {
+		if len(out.Results) == limit {
+			out.NextCursor = encodeCursor(searchCursor{Offset: after.Offset + limit})
+			break
+		}
+
+		var hit SearchHit
+		if err := rows.Scan(
+			&hit.Type,
+			&hit.ID,
+			&hit.ProjectID,
+			&hit.ProjectName,
+			&hit.Title,
+			&hit.Highlight,
+			&hit.Snippet,
+			&hit.Rank,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		hit.Highlight = markHeadline(hit.Highlight)
+		hit.Snippet = markHeadline(hit.Snippet)
+		out.Results = append(out.Results, hit)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// markHeadline HTML-escapes a ts_headline result and turns its match
+// delimiters into <mark> tags.
+func markHeadline(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, headlineStart, "<mark>")
+	return strings.ReplaceAll(s, headlineStop, "</mark>")
+}
@@ -0,0 +1,765 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Sprint statuses
+const (
+	SprintPlanned = "planned"
+	SprintActive  = "active"
+	SprintClosed  = "closed"
+)
+
+// ========= Sprint DTOs (responses) =========
+type Sprint struct {
+	ID        string  `json:"id"`
+	ProjectID string  `json:"project_id"`
+	Name      string  `json:"name"`
+	Goal      string  `json:"goal"`
+	StartDate string  `json:"start_date"` // YYYY-MM-DD
+	EndDate   string  `json:"end_date"`
+	Status    string  `json:"status"` // planned | active | closed
+	StartedAt *string `json:"started_at"`
+	ClosedAt  *string `json:"closed_at"`
+	// current contents; points are task difficulty
+	TaskCount  int `json:"task_count"`
+	Points     int `json:"points"`
+	DoneCount  int `json:"done_count"`
+	DonePoints int `json:"done_points"`
+}
+
+type SprintPoints struct {
+	Tasks  int `json:"tasks"`
+	Points int `json:"points"`
+}
+
+// SprintSummary compares what a sprint committed to when it started with
+// what got done. Planned sprints report their current contents as
+// committed; closed sprints report the figures recorded when they closed,
+// with Remaining being what was carried over.
+type SprintSummary struct {
+	Sprint            Sprint       `json:"sprint"`
+	Committed         SprintPoints `json:"committed"`
+	Completed         SprintPoints `json:"completed"`
+	Remaining         SprintPoints `json:"remaining"`
+	CompletionPercent int          `json:"completion_percent"` // completed vs committed points
+}
+
+// ========= Requests =========
+type createSprintReq struct {
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type updateSprintReq struct {
+	Name      *string `json:"name"`
+	Goal      *string `json:"goal"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+// closeSprintReq names the planned sprint unfinished tasks move to; omitted
+// or "" sends them back to the backlog (no sprint).
+type closeSprintReq struct {
+	CarryOverTo *string `json:"carry_over_to"`
+}
+
+func (h *Handler) ListSprints(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+sprintColumns+`
+		from sprints s
+		where s.project_id::text = $1
+		order by s.start_date, s.created_at, s.id
+	`, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []Sprint{}
+	for rows.Next() {
+		s, err := scanSprint(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) CreateSprint(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req createSprintReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+	start, err := time.Parse(time.DateOnly, strings.TrimSpace(req.StartDate))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date"})
+		return
+	}
+	end, err := time.Parse(time.DateOnly, strings.TrimSpace(req.EndDate))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date"})
+		return
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date is before start_date"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	if !requireWritableProject(c, ctx, h.DB, projectID) {
+		return
+	}
+
+	var sprintID string
+	if err := h.DB.QueryRow(ctx, `
+		insert into sprints (project_id, name, goal, start_date, end_date)
+		values ($1::uuid, $2, $3, $4, $5)
+		returning id::text
+	`, projectID, name, strings.TrimSpace(req.Goal), start, end).Scan(&sprintID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadSprint(ctx, h.DB, projectID, sprintID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// UpdateSprint edits a sprint that hasn't closed yet.
+func (h *Handler) UpdateSprint(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, sprintID, ok := parseSprintPath(c)
+	if !ok {
+		return
+	}
+
+	var req updateSprintReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+			return
+		}
+		req.Name = &n
+	}
+	if req.Goal != nil {
+		g := strings.TrimSpace(*req.Goal)
+		req.Goal = &g
+	}
+	var start, end *time.Time
+	if req.StartDate != nil {
+		t, err := time.Parse(time.DateOnly, strings.TrimSpace(*req.StartDate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date"})
+			return
+		}
+		start = &t
+	}
+	if req.EndDate != nil {
+		t, err := time.Parse(time.DateOnly, strings.TrimSpace(*req.EndDate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date"})
+			return
+		}
+		end = &t
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	current, ok := requireSprintAccess(c, ctx, tx, projectID, sprintID, uid)
+	if !ok {
+		return
+	}
+	if current.Status == SprintClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "sprint is closed"})
+		return
+	}
+
+	// the dates are checked together, old or new
+	newStart, newEnd := current.StartDate, current.EndDate
+	if start != nil {
+		newStart = start.Format(time.DateOnly)
+	}
+	if end != nil {
+		newEnd = end.Format(time.DateOnly)
+	}
+	if newEnd < newStart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date is before start_date"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update sprints
+		set name = coalesce($3, name),
+			goal = coalesce($4, goal),
+			start_date = coalesce($5, start_date),
+			end_date = coalesce($6, end_date)
+		where project_id::text = $1 and id::text = $2
+	`, projectID, sprintID, req.Name, req.Goal, start, end); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadSprint(ctx, tx, projectID, sprintID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// DeleteSprint removes a sprint that isn't running; its tasks stay in the
+// project without a sprint.
+func (h *Handler) DeleteSprint(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, sprintID, ok := parseSprintPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	current, ok := requireSprintAccess(c, ctx, tx, projectID, sprintID, uid)
+	if !ok {
+		return
+	}
+	if current.Status == SprintActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "sprint is active"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from sprints
+		where project_id::text = $1 and id::text = $2
+	`, projectID, sprintID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// StartSprint makes a planned sprint the project's active one and records
+// what it committed to.
+func (h *Handler) StartSprint(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, sprintID, ok := parseSprintPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	current, ok := requireSprintAccess(c, ctx, tx, projectID, sprintID, uid)
+	if !ok {
+		return
+	}
+	if current.Status != SprintPlanned {
+		c.JSON(http.StatusConflict, gin.H{"error": "sprint is " + current.Status})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update sprints s
+		set status = 'active',
+			started_at = now(),
+			committed_tasks = c.tasks,
+			committed_points = c.points
+		from (
+			select count(*)::int as tasks, coalesce(sum(difficulty), 0)::int as points
+			from tasks
+			where sprint_id::text = $2
+		) c
+		where s.project_id::text = $1 and s.id::text = $2
+	`, projectID, sprintID); err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "another sprint is active"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadSprint(ctx, tx, projectID, sprintID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// CloseSprint ends the active sprint. Done tasks stay with it; the rest
+// carry over to the named planned sprint, or to no sprint.
+func (h *Handler) CloseSprint(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, sprintID, ok := parseSprintPath(c)
+	if !ok {
+		return
+	}
+
+	var req closeSprintReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+			return
+		}
+	}
+
+	var target *string
+	if req.CarryOverTo != nil {
+		if v := strings.ToLower(strings.TrimSpace(*req.CarryOverTo)); v != "" {
+			if _, err := uuid.Parse(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid carry_over_to"})
+				return
+			}
+			if v == sprintID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cannot carry over into the same sprint"})
+				return
+			}
+			target = &v
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	current, ok := requireSprintAccess(c, ctx, tx, projectID, sprintID, uid)
+	if !ok {
+		return
+	}
+	if current.Status != SprintActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "sprint is " + current.Status})
+		return
+	}
+
+	if target != nil {
+		var status string
+		if err := tx.QueryRow(ctx, `
+			select status from sprints
+			where project_id::text = $1 and id::text = $2
+		`, projectID, *target).Scan(&status); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "carry-over sprint not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if status != SprintPlanned {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "carry-over sprint must be planned"})
+			return
+		}
+	}
+
+	// Record the outcome before unfinished tasks move out
+	if _, err := tx.Exec(ctx, `
+		update sprints s
+		set status = 'closed',
+			closed_at = now(),
+			completed_tasks = o.done_tasks,
+			completed_points = o.done_points,
+			carried_tasks = o.open_tasks,
+			carried_points = o.open_points
+		from (
+			select
+				count(*) filter (where status = 'done')::int as done_tasks,
+				coalesce(sum(difficulty) filter (where status = 'done'), 0)::int as done_points,
+				count(*) filter (where status <> 'done')::int as open_tasks,
+				coalesce(sum(difficulty) filter (where status <> 'done'), 0)::int as open_points
+			from tasks
+			where sprint_id::text = $2
+		) o
+		where s.project_id::text = $1 and s.id::text = $2
+	`, projectID, sprintID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		update tasks
+		set sprint_id = $3::uuid,
+			version = version + 1
+		where project_id::text = $1 and sprint_id::text = $2 and status <> 'done'
+	`, projectID, sprintID, target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadSprintSummary(ctx, tx, projectID, sprintID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) GetSprintSummary(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, sprintID, ok := parseSprintPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	out, err := loadSprintSummary(ctx, h.DB, projectID, sprintID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "sprint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// requireSprintAccess checks the caller can change the project's sprints
+// and locks the sprint row, writing the error response otherwise.
+func requireSprintAccess(c *gin.Context, ctx context.Context, tx pgx.Tx, projectID, sprintID, uid string) (Sprint, bool) {
+	allowed, err := isProjectMember(ctx, tx, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return Sprint{}, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return Sprint{}, false
+	}
+	if !requireWritableProject(c, ctx, tx, projectID) {
+		return Sprint{}, false
+	}
+
+	if _, err := tx.Exec(ctx, `
+		select 1 from sprints
+		where project_id::text = $1 and id::text = $2
+		for update
+	`, projectID, sprintID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return Sprint{}, false
+	}
+
+	s, err := loadSprint(ctx, tx, projectID, sprintID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "sprint not found"})
+			return Sprint{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return Sprint{}, false
+	}
+	return s, true
+}
+
+// requireOpenSprint checks a task may be put in sprintID: it must be one of
+// the project's sprints that hasn't closed.
+func requireOpenSprint(c *gin.Context, ctx context.Context, q querier, projectID, sprintID string) bool {
+	if _, err := uuid.Parse(sprintID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint_id"})
+		return false
+	}
+
+	var status string
+	if err := q.QueryRow(ctx, `
+		select status from sprints
+		where project_id::text = $1 and id::text = $2
+	`, projectID, sprintID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sprint not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if status == SprintClosed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sprint is closed"})
+		return false
+	}
+	return true
+}
+
+func parseSprintPath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	sprintID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("sprintId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint id"})
+		return "", "", false
+	}
+	return projectID.String(), sprintID.String(), true
+}
+
+const sprintColumns = `
+	s.id::text,
+	s.project_id::text,
+	s.name,
+	s.goal,
+	s.start_date,
+	s.end_date,
+	s.status,
+	s.started_at,
+	s.closed_at,
+	(select count(*) from tasks t where t.sprint_id = s.id)::int,
+	(select coalesce(sum(t.difficulty), 0) from tasks t where t.sprint_id = s.id)::int,
+	(select count(*) from tasks t where t.sprint_id = s.id and t.status = 'done')::int,
+	(select coalesce(sum(t.difficulty), 0) from tasks t where t.sprint_id = s.id and t.status = 'done')::int
+`
+
+// loadSprint returns pgx.ErrNoRows when the sprint is not in the project.
+func loadSprint(ctx context.Context, q querier, projectID, sprintID string) (Sprint, error) {
+	return scanSprint(q.QueryRow(ctx, `
+		select `+sprintColumns+`
+		from sprints s
+		where s.project_id::text = $1 and s.id::text = $2
+	`, projectID, sprintID))
+}
+
+func loadSprintSummary(ctx context.Context, q querier, projectID, sprintID string) (SprintSummary, error) {
+	var out SprintSummary
+	var committed, completed, carried SprintPoints
+	s, err := scanSprint(q.QueryRow(ctx, `
+		select `+sprintColumns+`,
+			s.committed_tasks, s.committed_points,
+			s.completed_tasks, s.completed_points,
+			s.carried_tasks, s.carried_points
+		from sprints s
+		where s.project_id::text = $1 and s.id::text = $2
+	`, projectID, sprintID),
+		&committed.Tasks, &committed.Points,
+		&completed.Tasks, &completed.Points,
+		&carried.Tasks, &carried.Points,
+	)
+	if err != nil {
+		return SprintSummary{}, err
+	}
+	out.Sprint = s
+
+	current := SprintPoints{Tasks: s.DoneCount, Points: s.DonePoints}
+	open := SprintPoints{Tasks: s.TaskCount - s.DoneCount, Points: s.Points - s.DonePoints}
+	switch s.Status {
+	case SprintPlanned:
+		out.Committed = SprintPoints{Tasks: s.TaskCount, Points: s.Points}
+		out.Completed = current
+		out.Remaining = open
+	case SprintActive:
+		out.Committed = committed
+		out.Completed = current
+		out.Remaining = open
+	default:
+		out.Committed = committed
+		out.Completed = completed
+		out.Remaining = carried
+	}
+	if out.Committed.Points > 0 {
+		out.CompletionPercent = out.Completed.Points * 100 / out.Committed.Points
+	}
+	return out, nil
+}
+
+func scanSprint(row pgx.Row, extra ...any) (Sprint, error) {
+	var s Sprint
+	var start, end time.Time
+	var startedAt, closedAt *time.Time
+	dest := []any{
+		&s.ID,
+		&s.ProjectID,
+		&s.Name,
+		&s.Goal,
+		&start,
+		&end,
+		&s.Status,
+		&startedAt,
+		&closedAt,
+		&s.TaskCount,
+		&s.Points,
+		&s.DoneCount,
+		&s.DonePoints,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Sprint{}, err
+	}
+	s.StartDate = start.Format(time.DateOnly)
+	s.EndDate = end.Format(time.DateOnly)
+	if startedAt != nil {
+		v := startedAt.UTC().Format(time.RFC3339)
+		s.StartedAt = &v
+	}
+	if closedAt != nil {
+		v := closedAt.UTC().Format(time.RFC3339)
+		s.ClosedAt = &v
+	}
+	return s, nil
+}
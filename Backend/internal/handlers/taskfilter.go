@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TaskFilter is the filter language shared by the task listing query
+// parameters and saved views. Empty fields don't filter. User references may
+// be "me", resolved against whoever is asking, so one saved view works for
+// every member.
+type TaskFilter struct {
+	Statuses   []string `json:"statuses,omitempty"`
+	Priorities []string `json:"priorities,omitempty"`
+	Assignee   string   `json:"assignee,omitempty"` // user id, "me" or "none"
+	Watcher    string   `json:"watcher,omitempty"`  // user id or "me"
+	LabelIDs   []string `json:"label_ids,omitempty"`
+	Parent     string   `json:"parent,omitempty"`    // task id or "none"
+	Sprint     string   `json:"sprint,omitempty"`    // sprint id or "none"
+	Milestone  string   `json:"milestone,omitempty"` // milestone id or "none"
+	Difficulty []int    `json:"difficulty,omitempty"`
+	Text       string   `json:"text,omitempty"` // full-text, web-search syntax
+}
+
+// taskSorts maps the sort names a saved view may use to ORDER BY clauses.
+// Each ends on t.id so the order is total.
+var taskSorts = map[string]string{
+	"board":      taskStatusRank + `, t.rank asc, t.id asc`,
+	"priority":   taskPriorityRank + `, ` + taskStatusRank + `, t.rank asc, t.id asc`,
+	"newest":     `t.created_at desc, t.id desc`,
+	"oldest":     `t.created_at asc, t.id asc`,
+	"title":      `lower(t.title) asc, t.id asc`,
+	"difficulty": `t.difficulty desc, t.id asc`,
+}
+
+// taskPriorityRank orders priorities most urgent first.
+const taskPriorityRank = `case t.priority
+		when 'urgent' then 1
+		when 'high' then 2
+		when 'medium' then 3
+		when 'low' then 4
+		else 9
+	end`
+
+// taskFilterFromQuery reads ?status=, ?priority=, ?label= and ?difficulty=
+// (repeatable or comma-separated), ?assignee=, ?watcher=, ?parent=,
+// ?sprint=, ?milestone= and ?q=.
+func taskFilterFromQuery(c *gin.Context) (TaskFilter, error) {
+	f := TaskFilter{
+		Statuses:   splitQueryList(c, "status"),
+		Priorities: splitQueryList(c, "priority"),
+		Assignee:   c.Query("assignee"),
+		Watcher:    c.Query("watcher"),
+		LabelIDs:   splitQueryList(c, "label"),
+		Parent:     c.Query("parent"),
+		Sprint:     c.Query("sprint"),
+		Milestone:  c.Query("milestone"),
+		Text:       c.Query("q"),
+	}
+	for _, raw := range splitQueryList(c, "difficulty") {
+		d, err := strconv.Atoi(raw)
+		if err != nil {
+			return TaskFilter{}, errors.New("invalid difficulty")
+		}
+		f.Difficulty = append(f.Difficulty, d)
+	}
+
+	if err := f.normalize(); err != nil {
+		return TaskFilter{}, err
+	}
+	return f, nil
+}
+
+// normalize trims and lowercases ids and checks every value, returning an
+// error whose message is fit for a 400 response.
+func (f *TaskFilter) normalize() error {
+	for _, st := range f.Statuses {
+		if !isValidTaskStatus(st) {
+			return errors.New("invalid status")
+		}
+	}
+	for _, p := range f.Priorities {
+		if !isValidTaskPriority(p) {
+			return errors.New("invalid priority")
+		}
+	}
+	for _, d := range f.Difficulty {
+		if d < 1 || d > 5 {
+			return errors.New("invalid difficulty")
+		}
+	}
+
+	if len(f.LabelIDs) > 0 {
+		ids, ok := normalizeLabelIDs(f.LabelIDs)
+		if !ok {
+			return errors.New("invalid label")
+		}
+		f.LabelIDs = ids
+	}
+
+	f.Assignee = strings.ToLower(strings.TrimSpace(f.Assignee))
+	if !isUserRef(f.Assignee, true) {
+		return errors.New("invalid assignee")
+	}
+	f.Watcher = strings.ToLower(strings.TrimSpace(f.Watcher))
+	if !isUserRef(f.Watcher, false) {
+		return errors.New("invalid watcher")
+	}
+
+	for _, ref := range []struct {
+		v    *string
+		name string
+	}{{&f.Parent, "parent"}, {&f.Sprint, "sprint"}, {&f.Milestone, "milestone"}} {
+		*ref.v = strings.ToLower(strings.TrimSpace(*ref.v))
+		if *ref.v != "" && *ref.v != "none" {
+			if _, err := uuid.Parse(*ref.v); err != nil {
+				return errors.New("invalid " + ref.name)
+			}
+		}
+	}
+
+	f.Text = strings.TrimSpace(f.Text)
+	return nil
+}
+
+// where returns SQL conditions on tasks aliased as t, numbering its
+// placeholders after the ones already in args, and the extended args.
+func (f TaskFilter) where(uid string, args []any) ([]string, []any) {
+	var where []string
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(f.Statuses) > 0 {
+		add("t.status = any($%d)", f.Statuses)
+	}
+	if len(f.Priorities) > 0 {
+		add("t.priority = any($%d)", f.Priorities)
+	}
+	if len(f.Difficulty) > 0 {
+		add("t.difficulty = any($%d)", f.Difficulty)
+	}
+
+	switch f.Assignee {
+	case "":
+	case "none":
+		where = append(where, "not exists (select 1 from task_assignees ta where ta.task_id = t.id)")
+	default:
+		add("exists (select 1 from task_assignees ta where ta.task_id = t.id and ta.user_id = $%d::uuid)", resolveMe(f.Assignee, uid))
+	}
+
+	if f.Watcher != "" {
+		add("exists (select 1 from task_watchers tw where tw.task_id = t.id and tw.user_id = $%d::uuid)", resolveMe(f.Watcher, uid))
+	}
+
+	// labels match tasks carrying any of them
+	if len(f.LabelIDs) > 0 {
+		add("exists (select 1 from task_labels tl where tl.task_id = t.id and tl.label_id::text = any($%d))", f.LabelIDs)
+	}
+
+	for _, ref := range []struct{ v, col string }{
+		{f.Parent, "t.parent_id"},
+		{f.Sprint, "t.sprint_id"},
+		{f.Milestone, "t.milestone_id"},
+	} {
+		switch ref.v {
+		case "":
+		case "none":
+			where = append(where, ref.col+" is null")
+		default:
+			add(ref.col+" = $%d::uuid", ref.v)
+		}
+	}
+
+	if f.Text != "" {
+		add("t.search_tsv @@ websearch_to_tsquery('english', $%d)", f.Text)
+	}
+
+	return where, args
+}
+
+func splitQueryList(c *gin.Context, key string) []string {
+	var out []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// isUserRef accepts "", "me", a UUID and, when allowNone is set, "none".
+func isUserRef(s string, allowNone bool) bool {
+	if s == "" || s == "me" || (allowNone && s == "none") {
+		return true
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+func resolveMe(ref, uid string) string {
+	if ref == "me" {
+		return uid
+	}
+	return ref
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -13,33 +16,76 @@ import (
 // ========= Task DTOs (responses) =========
 type Task struct {
 	ID string					`json:"id"`
+	Key string					`json:"key"` // e.g. "FRG-42"; usable in URLs in place of id
+	Number int					`json:"number"`
 	ProjectID string			`json:"project_id"`
 	Title string				`json:"title"`
 	Details string				`json:"details"`
 	Status string				`json:"status"`
-	AssigneeID *string			`json:"assignee_id"`
-	AssigneeUsername *string	`json:"assignee_username"`
+	Assignees []TaskUser		`json:"assignees"`
+	Watchers []TaskUser			`json:"watchers"`
 	Difficulty int				`json:"difficulty"`
 	SortIndex int				`json:"sort_index"`
 	CreatedAt string			`json:"created_at"`
+	Version int					`json:"version"`
+	Rank string					`json:"rank"`
+	ParentID *string			`json:"parent_id"`
+	SubtaskCount int			`json:"subtask_count"`
+	SubtasksDone int			`json:"subtasks_done"`
+	ChecklistCount int			`json:"checklist_count"`
+	ChecklistDone int			`json:"checklist_done"`
+	Progress int				`json:"progress"`
+	Priority string				`json:"priority"`
+	Labels []Label				`json:"labels"`
+	EstimateHours *float64		`json:"estimate_hours"`
+	StoryPoints *int			`json:"story_points"`
+	LoggedMinutes int			`json:"logged_minutes"`
+	SprintID *string			`json:"sprint_id"`
+	MilestoneID *string			`json:"milestone_id"`
+}
+
+type TaskPage struct {
+	Tasks      []Task  `json:"tasks"`
+	NextCursor *string `json:"next_cursor"`
 }
 
 // ========= Requests =========
+type taskCursor struct {
+	StatusRank int    `json:"s"`
+	Rank       string `json:"r"`
+	ID         string `json:"id"`
+}
+
 type createTaskReq struct {
 	Title string		`json:"title"`
 	Details string		`json:"details"`
 	Status string		`json:"status"`
-	AssigneeID *string	`json:"assignee_id"`
+	AssigneeIDs []string	`json:"assignee_ids"`
 	Difficulty int		`json:"difficulty"`
 	SortIndex *int		`json:"sort_index"`
+	ParentID *string	`json:"parent_id"`
+	Priority string		`json:"priority"`
+	EstimateHours *float64	`json:"estimate_hours"`
+	StoryPoints *int	`json:"story_points"`
+	LabelIDs []string	`json:"label_ids"`
+	SprintID *string	`json:"sprint_id"`
+	MilestoneID *string	`json:"milestone_id"`
 }
 
 type updateTaskReq struct {
+    Title      *string `json:"title"`
     Details    *string `json:"details"`
     Status     *string `json:"status"`
-    AssigneeID *string `json:"assignee_id"`
+    AssigneeIDs *[]string `json:"assignee_ids"`
     Difficulty *int    `json:"difficulty"`
     SortIndex  *int    `json:"sort_index"`
+    ParentID   *string `json:"parent_id"`
+    Priority   *string `json:"priority"`
+    EstimateHours *float64 `json:"estimate_hours"` // 0 clears
+    StoryPoints   *int     `json:"story_points"`   // 0 clears
+    LabelIDs   *[]string `json:"label_ids"`
+    SprintID   *string `json:"sprint_id"`    // "" removes it from its sprint
+    MilestoneID *string `json:"milestone_id"` // "" clears
 }
 
 func (h *Handler) AddTask(c *gin.Context) {
@@ -93,27 +139,44 @@ func (h *Handler) AddTask(c *gin.Context) {
 		return
 	}
 
-	// Normalize assignee: treat missing/blank as NULL (unassigned)
-	var assignee any = nil
-	if req.AssigneeID != nil {
-		a := strings.TrimSpace(*req.AssigneeID)
-		if a != "" {
-			assignee = a // UUID string; postgres will cast to uuid
-		}
+	priority := strings.TrimSpace(req.Priority)
+	if priority == "" {
+		priority = "medium"
+	}
+	if !isValidTaskPriority(priority) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid priority"})
+		return
+	}
+
+	labelIDs, ok := normalizeLabelIDs(req.LabelIDs)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label id"})
+		return
+	}
+
+	if !validEstimate(c, req.EstimateHours, req.StoryPoints) {
+		return
+	}
+	estimateHours, storyPoints := nonZeroEstimate(req.EstimateHours, req.StoryPoints)
+
+	assigneeIDs, ok := normalizeUserIDs(req.AssigneeIDs)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee"})
+		return
 	}
 
 	ctx, cancel := contextTimeout(c, 5*time.Second)
 	defer cancel()
 
-	var allowed bool
-	if err := h.DB.QueryRow(ctx, `
-		select exists (
-			select 1
-			from projects_members
-			where project_id = $1
-			and user_id::text = $2
-		)
-	`, projectID, uid).Scan(&allowed); err != nil {
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	allowed, err := isProjectMember(ctx, tx, projectID.String(), uid)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
@@ -121,9 +184,40 @@ func (h *Handler) AddTask(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
 		return
 	}
+	if !requireWritableProject(c, ctx, tx, projectID.String()) {
+		return
+	}
+	if !requireAssignableMembers(c, ctx, tx, projectID.String(), assigneeIDs) {
+		return
+	}
 
-	var out Task
-	var createdAt time.Time
+	var parent any = nil
+	if req.ParentID != nil {
+		if p := strings.ToLower(strings.TrimSpace(*req.ParentID)); p != "" {
+			if !requireValidParent(c, ctx, tx, projectID.String(), "", p) {
+				return
+			}
+			parent = p
+		}
+	}
+
+	var sprint, milestone any = nil, nil
+	if req.SprintID != nil {
+		if id := strings.ToLower(strings.TrimSpace(*req.SprintID)); id != "" {
+			if !requireOpenSprint(c, ctx, tx, projectID.String(), id) {
+				return
+			}
+			sprint = id
+		}
+	}
+	if req.MilestoneID != nil {
+		if id := strings.ToLower(strings.TrimSpace(*req.MilestoneID)); id != "" {
+			if !requireMilestone(c, ctx, tx, projectID.String(), id) {
+				return
+			}
+			milestone = id
+		}
+	}
 
 	var sortIndex *int
 	if req.SortIndex != nil {
@@ -135,59 +229,180 @@ func (h *Handler) AddTask(c *gin.Context) {
 		sortIndex = &si
 	}
 
+	// Only the new row is written; its rank slots it between its neighbours
+	rank, err := taskRankFor(ctx, tx, projectID.String(), status, "", sortIndex)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
 
-	err := h.DB.QueryRow(ctx, `
-	with desired as (
-		select coalesce(
-			$7::int,
-			(select coalesce(max(sort_index), -1) + 1
-			from tasks
-			where project_id = $1 and status = $4)
-		) as idx
-	), shifted as (
-		update tasks
-		set sort_index = sort_index + 1
-		where project_id = $1
-			and status = $4
-			and $7::int is not null
-			and sort_index >= (select idx from desired)
-	), inserted as (
-		insert into tasks (project_id, title, details, status, assignee_id, difficulty, sort_index)
-		values ($1, $2, $3, $4, $5, $6, (select idx from desired))
-		returning *
-	)
-	select inserted.id::text,
-		inserted.project_id::text,
-		inserted.title,
-		inserted.details,
-		inserted.status,
-		inserted.assignee_id::text,
-		u.username,
-		inserted.difficulty,
-		inserted.sort_index,
-		inserted.created_at
-	from inserted
-	left join users u on u.id = inserted.assignee_id
-	`, projectID, title, details, status, assignee, diff, sortIndex).
-	Scan(
-		&out.ID,
-		&out.ProjectID,
-		&out.Title,
-		&out.Details,
-		&out.Status,
-		&out.AssigneeID,
-		&out.AssigneeUsername,
-		&out.Difficulty,
-		&out.SortIndex,
-		&createdAt,
-	)
+	// The project row's lock serializes concurrent adds, so numbers are
+	// handed out once each and in order
+	var taskID string
+	err = tx.QueryRow(ctx, `
+		with seq as (
+			update projects
+			set task_seq = task_seq + 1
+			where id = $1
+			returning task_seq
+		)
+		insert into tasks (project_id, title, details, status, difficulty, rank, parent_id, priority, estimate_hours, story_points, sprint_id, milestone_id, number)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, (select task_seq from seq))
+		returning id::text
+	`, projectID, title, details, status, diff, rank, parent, priority, estimateHours, storyPoints, sprint, milestone).Scan(&taskID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if len(labelIDs) > 0 {
+		known, err := setTaskLabels(ctx, tx, projectID.String(), taskID, labelIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !known {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown label"})
+			return
+		}
+	}
+
+	if len(assigneeIDs) > 0 {
+		added, err := setTaskAssignees(ctx, tx, taskID, assigneeIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if err := notifyUsers(ctx, tx, added, taskID, uid, NotificationTaskAssigned); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+	}
+
+	out, err := loadTask(ctx, tx, projectID.String(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	setETag(c, out.Version)
+	c.JSON(http.StatusOK, out)
+}
+
+// ListTasks returns one project's tasks in board order, filtered by
+// ?status= (repeatable or comma-separated), ?assignee= (user id, "me" or
+// "none"), ?watcher= (user id or "me"), ?priority=, ?label= (label ids, any
+// of), ?parent= (task id for its subtasks, "none" for top-level tasks),
+// ?sprint= and ?milestone= (id or "none") and ?difficulty= (any of), plus
+// ?q= for full-text matches, with cursor pagination. Saved views use the
+// same filters (see TaskFilter).
+func (h *Handler) ListTasks(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	filter, err := taskFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Build the WHERE clause from whichever filters were given
+	where, args := filter.where(uid, []any{projectUUID})
+	where = append([]string{"t.project_id = $1"}, where...)
+	i := len(args) + 1
+
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		var after taskCursor
+		err := decodeCursor(raw, &after)
+		if err == nil {
+			_, err = uuid.Parse(after.ID) // compared as ::uuid below
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		where = append(where, fmt.Sprintf(
+			"(%s, t.rank, t.id) > ($%d, $%d, $%d::uuid)",
+			taskStatusRank, i, i+1, i+2,
+		))
+		args = append(args, after.StatusRank, after.Rank, after.ID)
+		i += 3
+	}
+
+	args = append(args, limit+1)
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
 
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	q := fmt.Sprintf(`
+		select %s, %s as status_rank
+		from tasks t
+		where %s
+		order by status_rank, t.rank asc, t.id asc
+		limit $%d
+	`, taskColumns, taskStatusRank, strings.Join(where, " and "), i)
+
+	rows, err := h.DB.Query(ctx, q, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := TaskPage{Tasks: []Task{}}
+	var last taskCursor
+	for rows.Next() {
+		if len(out.Tasks) == limit {
+			// the extra row only tells us there is another page
+			out.NextCursor = encodeCursor(last)
+			break
+		}
+
+		var statusRank int
+		t, err := scanTask(rows, &statusRank)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		last = taskCursor{StatusRank: statusRank, Rank: t.Rank, ID: t.ID}
+		out.Tasks = append(out.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
 
-	out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
 	c.JSON(http.StatusOK, out)
 }
 
@@ -227,13 +442,17 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-    // sortIndex is ALWAYS required (all edit modes provide it)
-	if req.SortIndex == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing sort_index"})
-		return
+	// Every field is optional; omitted fields keep their current value
+	if req.Title != nil {
+		t := strings.TrimSpace(*req.Title)
+		if t == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing title"})
+			return
+		}
+		*req.Title = t
 	}
 
-	if *req.SortIndex < 0 {
+	if req.SortIndex != nil && *req.SortIndex < 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort_index"})
 		return
 	}
@@ -256,19 +475,47 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 		*req.Status = s
 	}
 
+	if req.Priority != nil {
+		p := strings.TrimSpace(*req.Priority)
+		if !isValidTaskPriority(p) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid priority"})
+			return
+		}
+		*req.Priority = p
+	}
+
+	if !validEstimate(c, req.EstimateHours, req.StoryPoints) {
+		return
+	}
+
+	var labelIDs []string
+	if req.LabelIDs != nil {
+		ids, ok := normalizeLabelIDs(*req.LabelIDs)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid label id"})
+			return
+		}
+		labelIDs = ids
+	}
+
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := contextTimeout(c, 8*time.Second)
 	defer cancel()
 
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	// Must be a project member
-	var allowed bool
-	if err := h.DB.QueryRow(ctx, `
-		select exists (
-			select 1
-			from projects_members
-			where project_id = $1
-			and user_id::text = $2
-		)
-	`, projectUUID, uid).Scan(&allowed); err != nil {
+	allowed, err := isProjectMember(ctx, tx, projectUUID.String(), uid)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
@@ -276,15 +523,20 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
 		return
 	}
+	if !requireWritableProject(c, ctx, tx, projectUUID.String()) {
+		return
+	}
 
-	// Fetch current status + sort_index (needed for stable reindexing)
+	// Fetch (and lock) current status and the version the client's If-Match
+	// is checked against
 	var oldStatus string
-	var oldIndex int
-	if err := h.DB.QueryRow(ctx, `
-		select status, sort_index
+	var version int
+	if err := tx.QueryRow(ctx, `
+		select status, version
 		from tasks
 		where project_id = $1 and id = $2
-	`, projectUUID, taskUUID).Scan(&oldStatus, &oldIndex); err != nil {
+		for update
+	`, projectUUID, taskUUID).Scan(&oldStatus, &version); err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 			return
@@ -293,12 +545,20 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	if expected != nil && *expected != version {
+		current, err := loadTask(ctx, tx, projectUUID.String(), taskUUID.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		versionConflict(c, current.Version, current)
+		return
+	}
+
 	newStatus := oldStatus
 	if req.Status != nil {
 		newStatus = *req.Status
 	}
-	newIndex := *req.SortIndex
-
 
 	// Normalize fields
 	var newDetails *string
@@ -310,121 +570,115 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 	var newDiff *int
 	if req.Difficulty != nil { newDiff = req.Difficulty }
 
-    // Assignee behavior:
-	// - If field omitted => keep as-is
-	// - If provided as "" => set NULL (unassigned)
-	// - If provided as uuid string => set that uuid
-	assigneeMode := "keep" // keep | null | set
-	var assigneeVal any = nil
-	if req.AssigneeID != nil {
-		v := strings.TrimSpace(*req.AssigneeID)
-		if v == "" {
-			assigneeMode = "null"
-			assigneeVal = nil
+	// assignee_ids replaces the whole set; [] unassigns everyone
+	var assigneeIDs []string
+	if req.AssigneeIDs != nil {
+		ids, ok := normalizeUserIDs(*req.AssigneeIDs)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignee"})
+			return
+		}
+		if !requireAssignableMembers(c, ctx, tx, projectUUID.String(), ids) {
+			return
+		}
+		assigneeIDs = ids
+	}
+
+	// Parent: omitted keeps, "" detaches, a task id makes this a subtask of it
+	parentMode := "keep"
+	var parentVal any = nil
+	if req.ParentID != nil {
+		if v := strings.ToLower(strings.TrimSpace(*req.ParentID)); v == "" {
+			parentMode = "null"
 		} else {
-			assigneeMode = "set"
-			assigneeVal = strings.ToLower(v)
+			if !requireValidParent(c, ctx, tx, projectUUID.String(), taskUUID.String(), v) {
+				return
+			}
+			parentMode = "set"
+			parentVal = v
 		}
 	}
 
-	// Reindex + update in a single statement.
-	// This keeps sort_index unique within each (project_id, status) bucket.
+	// Sprint and milestone: omitted keeps, "" clears, an id sets
+	var sprintVal, milestoneVal *string
+	if req.SprintID != nil {
+		v := strings.ToLower(strings.TrimSpace(*req.SprintID))
+		if v != "" && !requireOpenSprint(c, ctx, tx, projectUUID.String(), v) {
+			return
+		}
+		sprintVal = &v
+	}
+	if req.MilestoneID != nil {
+		v := strings.ToLower(strings.TrimSpace(*req.MilestoneID))
+		if v != "" && !requireMilestone(c, ctx, tx, projectUUID.String(), v) {
+			return
+		}
+		milestoneVal = &v
+	}
 
-	var out Task
-	var createdAt time.Time
-	
-	err = h.DB.QueryRow(ctx, `
-		with cur as (
-			select id, project_id, status as old_status, sort_index as old_index
-			from tasks
-			where project_id = $1 and id = $2
-		),
-		move_same as (
-			-- Reorder within the same status
-			update tasks t
-			set sort_index = case
-				when (select $4::int) > (select old_index from cur)
-					and t.sort_index > (select old_index from cur)
-					and t.sort_index <= (select $4::int)
-					then t.sort_index - 1
-				when (select $4::int) < (select old_index from cur)
-					and t.sort_index >= (select $4::int)
-					and t.sort_index < (select old_index from cur)
-					then t.sort_index + 1
-				else t.sort_index
-			end
-			where t.project_id = $1
-				and t.status = (select old_status from cur)
-				and t.id <> (select id from cur)
-				and (select $3) = (select old_status from cur)
-		),
-		move_cross_old as (
-			-- Close gap in old status when changing status
-			update tasks t
-			set sort_index = t.sort_index - 1
-			where t.project_id = $1
-				and t.status = (select old_status from cur)
-				and t.sort_index > (select old_index from cur)
-				and (select $3) <> (select old_status from cur)
-		),
-		move_cross_new as (
-			-- Make room in new status when changing status
-			update tasks t
-			set sort_index = t.sort_index + 1
-			where t.project_id = $1
-				and t.status = (select $3)
-				and t.sort_index >= (select $4::int)
-				and (select $3) <> (select old_status from cur)
-		),
-		updated as (
-			update tasks
-			set
-				details = coalesce($5, details),
-				status = $3,
-				sort_index = $4::int,
-				difficulty = coalesce($6, difficulty),
-				assignee_id = case
-					when $7 = 'keep' then assignee_id
-					when $7 = 'null' then null
-					else $8::uuid
-				end
-			where project_id = $1 and id = $2
-			returning *
-		)
-		select
-			u.id::text,
-			u.project_id::text,
-			u.title,
-			u.details,
-			u.status,
-			u.assignee_id::text,
-			usr.username,
-			u.difficulty,
-			u.sort_index,
-			u.created_at
-		from updated u
-		left join users usr on usr.id = u.assignee_id
+	// The rank only changes on a move: an explicit position, or a status
+	// change without one, which appends to the new column. Siblings are
+	// left untouched.
+	var newRank *string
+	if req.SortIndex != nil || newStatus != oldStatus {
+		r, err := taskRankFor(ctx, tx, projectUUID.String(), newStatus, taskUUID.String(), req.SortIndex)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		newRank = &r
+	}
+
+	var taskID string
+	err = tx.QueryRow(ctx, `
+		update tasks
+		set
+			title = coalesce($7, title),
+			details = coalesce($5, details),
+			status = $3,
+			rank = coalesce($4, rank),
+			difficulty = coalesce($6, difficulty),
+			parent_id = case
+				when $8 = 'keep' then parent_id
+				when $8 = 'null' then null
+				else $9::uuid
+			end,
+			priority = coalesce($10, priority),
+			estimate_hours = case
+				when $11::numeric is null then estimate_hours
+				else nullif($11::numeric, 0)
+			end,
+			story_points = case
+				when $12::int is null then story_points
+				else nullif($12::int, 0)
+			end,
+			sprint_id = case
+				when $13::text is null then sprint_id
+				else nullif($13::text, '')::uuid
+			end,
+			milestone_id = case
+				when $14::text is null then milestone_id
+				else nullif($14::text, '')::uuid
+			end,
+			version = version + 1
+		where project_id = $1 and id = $2
+		returning id::text
 	`,
 		projectUUID,
 		taskUUID,
 		newStatus,
-		newIndex,
+		newRank,
 		newDetails,
 		newDiff,
-		assigneeMode,
-		assigneeVal,
-	).Scan(
-		&out.ID,
-		&out.ProjectID,
-		&out.Title,
-		&out.Details,
-		&out.Status,
-		&out.AssigneeID,
-		&out.AssigneeUsername,
-		&out.Difficulty,
-		&out.SortIndex,
-		&createdAt,
-	)
+		req.Title,
+		parentMode,
+		parentVal,
+		req.Priority,
+		req.EstimateHours,
+		req.StoryPoints,
+		sprintVal,
+		milestoneVal,
+	).Scan(&taskID)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -436,7 +690,50 @@ func (h *Handler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	// label_ids replaces the whole set; [] clears it
+	if req.LabelIDs != nil {
+		known, err := setTaskLabels(ctx, tx, projectUUID.String(), taskID, labelIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !known {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown label"})
+			return
+		}
+	}
+
+	// New assignees hear they were assigned; everyone else following the
+	// task hears it changed
+	var added []string
+	if req.AssigneeIDs != nil {
+		added, err = setTaskAssignees(ctx, tx, taskID, assigneeIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+	}
+	if err := notifyUsers(ctx, tx, added, taskID, uid, NotificationTaskAssigned); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if err := notifyTaskFollowers(ctx, tx, taskID, uid, NotificationTaskUpdated, added); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadTask(ctx, tx, projectUUID.String(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	setETag(c, out.Version)
 	c.JSON(http.StatusOK, out)
 }
 
@@ -470,18 +767,16 @@ func (h *Handler) DeleteTask(c *gin.Context) {
 		return
 	}
 
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := contextTimeout(c, 8*time.Second)
 	defer cancel()
 
-	var allowed bool
-	if err := h.DB.QueryRow(ctx, `
-		select exists (
-			select 1
-			from projects_members
-			where project_id = $1
-			and user_id::text = $2
-		)
-	`, projectUUID, uid).Scan(&allowed); err != nil {
+	allowed, err := isProjectMember(ctx, h.DB, projectUUID.String(), uid)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
 		return
 	}
@@ -489,19 +784,23 @@ func (h *Handler) DeleteTask(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
 		return
 	}
+	if !requireWritableProject(c, ctx, h.DB, projectUUID.String()) {
+		return
+	}
 
 	tx, err := h.DB.Begin(ctx)
     if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error":"server error"}); return }
     defer tx.Rollback(ctx)
 
-    // 1) read the task's status + sort_index (and ensure it belongs to project)
+    // 1) read the task's status (and ensure it belongs to project)
     var status string
-    var deletedSort int
+    var version int
     err = tx.QueryRow(ctx, `
-        select status, sort_index
+        select status, version
         from tasks
         where id::text = $1 and project_id::text = $2
-    `, taskUUID, projectUUID).Scan(&status, &deletedSort)
+        for update
+    `, taskUUID, projectUUID).Scan(&status, &version)
     if err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error":"task not found"})
@@ -511,7 +810,23 @@ func (h *Handler) DeleteTask(c *gin.Context) {
 		return
     }
 
-    // 2) delete the task
+    if expected != nil && *expected != version {
+		current, err := loadTask(ctx, tx, projectUUID.String(), taskUUID.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		versionConflict(c, current.Version, current)
+		return
+    }
+
+    // 2) let followers know while the task (and its followers) still exist
+    if err := notifyTaskFollowers(ctx, tx, taskUUID.String(), uid, NotificationTaskDeleted, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+    }
+
+    // 3) delete the task
     cmd, err := tx.Exec(ctx, `
         delete from tasks
         where id::text = $1 and project_id::text = $2
@@ -522,16 +837,6 @@ func (h *Handler) DeleteTask(c *gin.Context) {
         return
     }
 
-    // 3) close the gap in that column
-    _, err = tx.Exec(ctx, `
-        update tasks
-        set sort_index = sort_index - 1
-        where project_id::text = $1
-			and status = $2
-			and sort_index > $3
-    `, projectUUID, status, deletedSort)
-    if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error":"server error"}); return }
-
     if err := tx.Commit(ctx); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error":"server error"}); return
     }
@@ -539,6 +844,268 @@ func (h *Handler) DeleteTask(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"ok": true, "status": status})
 }
 
+// taskColumns lists the columns scanTask expects, in order. Queries must
+// alias tasks as t.
+const taskColumns = `
+	t.id::text,
+	(select p.key from projects p where p.id = t.project_id),
+	t.number,
+	t.project_id::text,
+	t.title,
+	coalesce(t.details, ''),
+	t.status,
+	t.difficulty,
+	`+taskPosition+`,
+	t.created_at,
+	t.version,
+	t.rank,
+	coalesce(t.parent_id::text, ''),
+	(select count(*) from tasks c where c.parent_id = t.id)::int,
+	(select count(*) from tasks c where c.parent_id = t.id and c.status = 'done')::int,
+	(select count(*) from task_checklist_items ci where ci.task_id = t.id)::int,
+	(select count(*) from task_checklist_items ci where ci.task_id = t.id and ci.is_done)::int,
+	t.priority,
+	coalesce((
+		select json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) order by lower(l.name), l.id)
+		from task_labels tl
+		join project_labels l on l.id = tl.label_id
+		where tl.task_id = t.id
+	), '[]'::json),
+	coalesce((
+		select json_agg(json_build_object('id', au.id, 'username', au.username) order by lower(au.username), au.id)
+		from task_assignees ta
+		join users au on au.id = ta.user_id
+		where ta.task_id = t.id
+	), '[]'::json),
+	coalesce((
+		select json_agg(json_build_object('id', wu.id, 'username', wu.username) order by lower(wu.username), wu.id)
+		from task_watchers tw
+		join users wu on wu.id = tw.user_id
+		where tw.task_id = t.id
+	), '[]'::json),
+	t.estimate_hours::float8,
+	t.story_points,
+	coalesce((
+		select sum(`+entryMinutes+`)
+		from time_entries te
+		where te.task_id = t.id
+	), 0)::int,
+	coalesce(t.sprint_id::text, ''),
+	coalesce(t.milestone_id::text, '')`
+
+// taskStatusRank orders board columns left to right.
+const taskStatusRank = `case t.status
+		when 'backlog' then 1
+		when 'inProgress' then 2
+		when 'blocked' then 3
+		when 'done' then 4
+		else 9
+	end`
+
+// loadTask returns pgx.ErrNoRows when the task is not in the project.
+func loadTask(ctx context.Context, q querier, projectID, taskID string) (Task, error) {
+	return scanTask(q.QueryRow(ctx, `
+		select `+taskColumns+`
+		from tasks t
+		where t.project_id::text = $1 and t.id::text = $2
+	`, projectID, taskID))
+}
+
+// scanTask reads taskColumns; extra receives any columns selected after them.
+func scanTask(row pgx.Row, extra ...any) (Task, error) {
+	var t Task
+	var createdAt time.Time
+	var parentID, projectKey, sprintID, milestoneID string
+
+	dest := []any{
+		&t.ID,
+		&projectKey,
+		&t.Number,
+		&t.ProjectID,
+		&t.Title,
+		&t.Details,
+		&t.Status,
+		&t.Difficulty,
+		&t.SortIndex,
+		&createdAt,
+		&t.Version,
+		&t.Rank,
+		&parentID,
+		&t.SubtaskCount,
+		&t.SubtasksDone,
+		&t.ChecklistCount,
+		&t.ChecklistDone,
+		&t.Priority,
+		&t.Labels,
+		&t.Assignees,
+		&t.Watchers,
+		&t.EstimateHours,
+		&t.StoryPoints,
+		&t.LoggedMinutes,
+		&sprintID,
+		&milestoneID,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Task{}, err
+	}
+
+	if parentID != "" {
+		t.ParentID = &parentID
+	}
+	if sprintID != "" {
+		t.SprintID = &sprintID
+	}
+	if milestoneID != "" {
+		t.MilestoneID = &milestoneID
+	}
+	t.Key = taskKey(projectKey, t.Number)
+	t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	t.Progress = taskProgress(t)
+	return t, nil
+}
+
+// taskProgress is the share of a task's subtasks and checklist items that are
+// done, as a percentage. A task with neither is 0 or 100 by its own status.
+func taskProgress(t Task) int {
+	total := t.SubtaskCount + t.ChecklistCount
+	if total == 0 {
+		if t.Status == "done" {
+			return 100
+		}
+		return 0
+	}
+	return (t.SubtasksDone + t.ChecklistDone) * 100 / total
+}
+
+// projectProgress averages the progress of a project's top-level tasks;
+// subtasks already count towards their parent.
+func projectProgress(tasks []Task) int {
+	sum, n := 0, 0
+	for _, t := range tasks {
+		if t.ParentID != nil {
+			continue
+		}
+		sum += t.Progress
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
+// parseTaskPath reads the :projectId and :taskId path params, responding
+// with a 400 when either is not a UUID.
+func parseTaskPath(c *gin.Context) (string, string, bool) {
+	projectID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return "", "", false
+	}
+	taskID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("taskId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return "", "", false
+	}
+	return projectID.String(), taskID.String(), true
+}
+
+// requireTaskAccess responds and returns false unless userID is a member of
+// the project and the task belongs to it. With write set the project must
+// also be writable, and the task row is locked until the transaction ends.
+func requireTaskAccess(c *gin.Context, ctx context.Context, q querier, projectID, taskID, userID string, write bool) bool {
+	allowed, err := isProjectMember(ctx, q, projectID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return false
+	}
+	if write && !requireWritableProject(c, ctx, q, projectID) {
+		return false
+	}
+
+	lock := ""
+	if write {
+		lock = "for update"
+	}
+	var found bool
+	if err := q.QueryRow(ctx, `
+		select true from tasks
+		where project_id::text = $1 and id::text = $2
+		`+lock, projectID, taskID).Scan(&found); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	return true
+}
+
+// requireAssignableMember writes a 400 and returns false unless userID is a
+// member of the project; tasks can only be assigned to members.
+func requireAssignableMember(c *gin.Context, ctx context.Context, q querier, projectID, userID string) bool {
+	isMember, err := isProjectMember(ctx, q, projectID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "assignee is not a project member"})
+		return false
+	}
+	return true
+}
+
+// requireValidParent writes a 400 and returns false unless parentID can be
+// the parent of taskID (empty for a new task). Subtasks are one level deep:
+// the parent must be a top-level task of the same project and the task must
+// not have subtasks of its own.
+func requireValidParent(c *gin.Context, ctx context.Context, q querier, projectID, taskID, parentID string) bool {
+	if _, err := uuid.Parse(parentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent_id"})
+		return false
+	}
+	if parentID == taskID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task cannot be its own parent"})
+		return false
+	}
+
+	var parentIsSubtask, taskHasSubtasks bool
+	if err := q.QueryRow(ctx, `
+		select
+			p.parent_id is not null,
+			exists (select 1 from tasks c where c.parent_id::text = $3)
+		from tasks p
+		where p.project_id::text = $1 and p.id::text = $2
+	`, projectID, parentID, taskID).Scan(&parentIsSubtask, &taskHasSubtasks); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent task not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if parentIsSubtask || taskHasSubtasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subtasks cannot be nested"})
+		return false
+	}
+	return true
+}
+
+func isValidTaskPriority(p string) bool {
+	switch p {
+	case "urgent", "high", "medium", "low":
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidTaskStatus(s string) bool {
 	switch s {
 	case "backlog", "inProgress", "blocked", "done":
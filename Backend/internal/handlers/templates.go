@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Templates and clones reuse the export bundle: a template is a stored
+// bundle with the people stripped out, and a clone is an export fed straight
+// back into the importer.
+
+// ========= Template DTOs (responses) =========
+type ProjectTemplate struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	TaskCount   int            `json:"task_count"`
+	CreatedAt   string         `json:"created_at"`
+	Bundle      *ProjectBundle `json:"bundle,omitempty"` // only on GET of one template
+}
+
+// ========= Requests =========
+type saveTemplateReq struct {
+	Name        string `json:"name"` // defaults to the project's name
+	Description string `json:"description"`
+}
+
+// newProjectReq names the project created from a template or clone.
+type newProjectReq struct {
+	Name           string `json:"name"`
+	Key            string `json:"key"`
+	IncludeMembers bool   `json:"include_members"` // clone only
+}
+
+// SaveProjectTemplate stores the project's roles, labels and tasks as one of
+// the caller's templates. Members and assignments are left out.
+func (h *Handler) SaveProjectTemplate(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req saveTemplateReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 15*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	tx, err := h.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	bundle, err := exportProjectBundle(ctx, tx, projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	bundle.stripPeople()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = bundle.Project.Name
+	}
+
+	var templateID string
+	if err := tx.QueryRow(ctx, `
+		insert into project_templates (owner_id, name, description, bundle)
+		values ($1::uuid, $2, $3, $4)
+		returning id::text
+	`, uid, name, strings.TrimSpace(req.Description), bundle).Scan(&templateID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectTemplate{
+		ID:          templateID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		TaskCount:   len(bundle.Tasks),
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *Handler) ListTemplates(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select id::text, name, description, jsonb_array_length(bundle->'tasks'), created_at
+		from project_templates
+		where owner_id::text = $1
+		order by lower(name), created_at, id
+	`, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []ProjectTemplate{}
+	for rows.Next() {
+		var t ProjectTemplate
+		var createdAt time.Time
+		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.TaskCount, &createdAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetTemplate returns one template including its bundle.
+func (h *Handler) GetTemplate(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	templateID, ok := parseTemplatePath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	var t ProjectTemplate
+	var bundle ProjectBundle
+	var createdAt time.Time
+	if err := h.DB.QueryRow(ctx, `
+		select id::text, name, description, bundle, created_at
+		from project_templates
+		where id::text = $1 and owner_id::text = $2
+	`, templateID, uid).Scan(&t.ID, &t.Name, &t.Description, &bundle, &createdAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	t.TaskCount = len(bundle.Tasks)
+	t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	t.Bundle = &bundle
+
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	templateID, ok := parseTemplatePath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from project_templates
+		where id::text = $1 and owner_id::text = $2
+	`, templateID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// CreateProjectFromTemplate starts a new project owned by the caller from
+// one of their templates.
+func (h *Handler) CreateProjectFromTemplate(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	usrAny, _ := c.Get("usr")
+	usr, ok := usrAny.(string)
+	if !ok || usr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "bad auth"})
+		return
+	}
+
+	templateID, ok := parseTemplatePath(c)
+	if !ok {
+		return
+	}
+
+	var req newProjectReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 30*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var bundle ProjectBundle
+	if err := tx.QueryRow(ctx, `
+		select bundle
+		from project_templates
+		where id::text = $1 and owner_id::text = $2
+	`, templateID, uid).Scan(&bundle); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	h.createFromBundle(c, ctx, tx, uid, usr, &bundle, req)
+}
+
+// CloneProject copies a project the caller belongs to into a new project
+// they own. Members are invited to the copy only when asked, and only by
+// someone who manages the original.
+func (h *Handler) CloneProject(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	usrAny, _ := c.Get("usr")
+	usr, ok := usrAny.(string)
+	if !ok || usr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "bad auth"})
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var req newProjectReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 30*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+	// copying members sends them invites, which only managers may do
+	if req.IncludeMembers && !requireProjectManager(c, ctx, h.DB, projectID, uid) {
+		return
+	}
+
+	tx, err := h.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	bundle, err := exportProjectBundle(ctx, tx, projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !req.IncludeMembers {
+		bundle.stripPeople()
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		req.Name = bundle.Project.Name + " (copy)"
+	}
+
+	h.createFromBundle(c, ctx, tx, uid, usr, &bundle, req)
+}
+
+// createFromBundle imports bundle under the requested name and key, commits
+// tx and writes the ImportResult.
+func (h *Handler) createFromBundle(c *gin.Context, ctx context.Context, tx pgx.Tx, uid, usr string, bundle *ProjectBundle, req newProjectReq) {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		bundle.Project.Name = name
+		bundle.Project.Key = "" // derived from the new name unless given
+	}
+	if strings.TrimSpace(req.Key) != "" {
+		k, ok := normalizeProjectKey(req.Key)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key"})
+			return
+		}
+		bundle.Project.Key = k
+	}
+	if err := bundle.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectID, result, err := importProjectBundle(ctx, tx, uid, usr, bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	result.Project, err = loadProject(ctx, tx, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// stripPeople drops members and every per-person assignment from the bundle.
+func (b *ProjectBundle) stripPeople() {
+	b.Project.Owner = ""
+	b.Members = []BundleMember{}
+	for i := range b.Tasks {
+		t := &b.Tasks[i]
+		t.Assignees = []string{}
+		t.Watchers = []string{}
+		for j := range t.Checklist {
+			t.Checklist[j].Assignee = nil
+		}
+	}
+}
+
+func parseTemplatePath(c *gin.Context) (string, bool) {
+	templateID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("templateId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return "", false
+	}
+	return templateID.String(), true
+}
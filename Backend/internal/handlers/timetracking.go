@@ -0,0 +1,610 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxEntryMinutes caps a single manual entry at one day.
+const maxEntryMinutes = 24 * 60
+
+// entryMinutes is a time entry's length in whole minutes; a running timer
+// counts up to now. Queries must alias time_entries as te.
+const entryMinutes = `(extract(epoch from (coalesce(te.ended_at, now()) - te.started_at)) / 60)::int`
+
+// ========= Time DTOs (responses) =========
+type TimeEntry struct {
+	ID        string  `json:"id"`
+	TaskID    string  `json:"task_id"`
+	UserID    string  `json:"user_id"`
+	Username  string  `json:"username"`
+	StartedAt string  `json:"started_at"`
+	EndedAt   *string `json:"ended_at"`
+	Minutes   int     `json:"minutes"`
+	Note      string  `json:"note"`
+	IsRunning bool    `json:"is_running"`
+}
+
+type TimeReport struct {
+	ProjectID                string          `json:"project_id"`
+	EstimatedHours           float64         `json:"estimated_hours"`
+	StoryPoints              int             `json:"story_points"`
+	LoggedHours              float64         `json:"logged_hours"`
+	UnassignedEstimatedHours float64         `json:"unassigned_estimated_hours"`
+	ByMember                 []TimeReportRow `json:"by_member"`
+	ByRole                   []TimeReportRow `json:"by_role"`
+}
+
+// TimeReportRow is one member's (UserID set) or one role's totals.
+// Estimates of tasks with several assignees are split evenly between them.
+type TimeReportRow struct {
+	UserID         string  `json:"user_id,omitempty"`
+	Username       string  `json:"username,omitempty"`
+	RoleKey        string  `json:"role_key"`
+	EstimatedHours float64 `json:"estimated_hours"`
+	StoryPoints    float64 `json:"story_points"`
+	LoggedHours    float64 `json:"logged_hours"`
+}
+
+// ========= Requests =========
+type createTimeEntryReq struct {
+	StartedAt string `json:"started_at"` // RFC3339; defaults to Minutes ago
+	Minutes   int    `json:"minutes"`
+	Note      string `json:"note"`
+}
+
+type stopTimerReq struct {
+	Note string `json:"note"`
+}
+
+func (h *Handler) ListTimeEntries(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, false) {
+		return
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+timeEntryColumns+`
+		from time_entries te
+		join users u on u.id = te.user_id
+		where te.task_id::text = $1
+		order by te.started_at desc, te.id desc
+	`, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// AddTimeEntry logs time spent on a task after the fact.
+func (h *Handler) AddTimeEntry(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	var req createTimeEntryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Minutes < 1 || req.Minutes > maxEntryMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid minutes"})
+		return
+	}
+
+	duration := time.Duration(req.Minutes) * time.Minute
+	startedAt := time.Now().Add(-duration)
+	if raw := strings.TrimSpace(req.StartedAt); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid started_at"})
+			return
+		}
+		startedAt = t
+	}
+	if startedAt.Add(duration).After(time.Now().Add(time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entry ends in the future"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireTaskAccess(c, ctx, tx, projectID, taskID, uid, true) {
+		return
+	}
+
+	var entryID string
+	if err := tx.QueryRow(ctx, `
+		insert into time_entries (task_id, user_id, started_at, ended_at, note)
+		values ($1::uuid, $2::uuid, $3, $4, $5)
+		returning id::text
+	`, taskID, uid, startedAt, startedAt.Add(duration), strings.TrimSpace(req.Note)).Scan(&entryID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadTimeEntry(ctx, tx, entryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// DeleteTimeEntry removes one of the caller's own entries.
+func (h *Handler) DeleteTimeEntry(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+	entryID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("entryId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, true) {
+		return
+	}
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from time_entries
+		where id = $1 and task_id::text = $2 and user_id::text = $3
+	`, entryID, taskID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "time entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// StartTimer starts the caller's timer on a task. A user has at most one
+// running timer; starting another while one runs is a 409.
+func (h *Handler) StartTimer(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !requireTaskAccess(c, ctx, tx, projectID, taskID, uid, true) {
+		return
+	}
+
+	var entryID string
+	if err := tx.QueryRow(ctx, `
+		insert into time_entries (task_id, user_id, started_at)
+		values ($1::uuid, $2::uuid, now())
+		on conflict (user_id) where ended_at is null do nothing
+		returning id::text
+	`, taskID, uid).Scan(&entryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			running, err := loadRunningTimer(ctx, tx, uid)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+				return
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "timer already running", "timer": running})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadTimeEntry(ctx, tx, entryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// StopTimer stops the caller's running timer on a task. It works on
+// archived and trashed projects, and after leaving them, so a timer is
+// never stranded.
+func (h *Handler) StopTimer(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	// the body is optional
+	var req stopTimerReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	// no membership check: the entry is the caller's own, and someone who
+	// left the project (or saw it trashed) must still be able to stop it
+	var entryID string
+	if err := h.DB.QueryRow(ctx, `
+		update time_entries te
+		set ended_at = now(),
+			note = case when $4 = '' then te.note else $4 end
+		from tasks t
+		where t.id = te.task_id
+			and t.project_id::text = $1
+			and te.task_id::text = $2
+			and te.user_id::text = $3
+			and te.ended_at is null
+		returning te.id::text
+	`, projectID, taskID, uid, strings.TrimSpace(req.Note)).Scan(&entryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no running timer on this task"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadTimeEntry(ctx, h.DB, entryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetMyTimer returns the caller's running timer, or null.
+func (h *Handler) GetMyTimer(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	running, err := loadRunningTimer(ctx, h.DB, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timer": running})
+}
+
+// GetTimeReport compares estimated and logged time for a project, per member
+// and per role_key. ?from= and ?to= (RFC3339) limit which entries count.
+func (h *Handler) GetTimeReport(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectUUID, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("projectId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	projectID := projectUUID.String()
+
+	var from, to *time.Time
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := strings.TrimSpace(c.Query(p.key))
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.key})
+			return
+		}
+		*p.dst = &t
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	allowed, err := isProjectMember(ctx, h.DB, projectID, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+		return
+	}
+
+	out := TimeReport{ProjectID: projectID, ByMember: []TimeReportRow{}, ByRole: []TimeReportRow{}}
+	if err := h.DB.QueryRow(ctx, `
+		select
+			coalesce(sum(t.estimate_hours), 0)::float8,
+			coalesce(sum(t.story_points), 0)::int,
+			coalesce(sum(t.estimate_hours) filter (
+				where not exists (select 1 from task_assignees ta where ta.task_id = t.id)
+			), 0)::float8
+		from tasks t
+		where t.project_id::text = $1
+	`, projectID).Scan(&out.EstimatedHours, &out.StoryPoints, &out.UnassignedEstimatedHours); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	// Everyone who is a member or has logged time; people who left the
+	// project keep their logged time under the "former" role.
+	rows, err := h.DB.Query(ctx, `
+		with shares as (
+			select
+				ta.user_id,
+				t.estimate_hours / count(*) over (partition by t.id) as hours,
+				t.story_points::numeric / count(*) over (partition by t.id) as points
+			from task_assignees ta
+			join tasks t on t.id = ta.task_id
+			where t.project_id::text = $1
+		),
+		logged as (
+			select te.user_id, sum(`+entryMinutes+`) as minutes
+			from time_entries te
+			join tasks t on t.id = te.task_id
+			where t.project_id::text = $1
+				and ($2::timestamptz is null or te.started_at >= $2)
+				and ($3::timestamptz is null or te.started_at < $3)
+			group by te.user_id
+		),
+		people as (
+			select user_id from projects_members where project_id::text = $1
+			union
+			select user_id from logged
+		)
+		select
+			u.id::text,
+			u.username,
+			coalesce(pm.role_key, 'former'),
+			coalesce((select sum(s.hours) from shares s where s.user_id = u.id), 0)::float8,
+			coalesce((select sum(s.points) from shares s where s.user_id = u.id), 0)::float8,
+			coalesce(l.minutes, 0)::float8 / 60
+		from people
+		join users u on u.id = people.user_id
+		left join projects_members pm on pm.project_id::text = $1 and pm.user_id = u.id
+		left join logged l on l.user_id = u.id
+		order by lower(u.username) asc
+	`, projectID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	byRole := map[string]*TimeReportRow{}
+	for rows.Next() {
+		var r TimeReportRow
+		if err := rows.Scan(&r.UserID, &r.Username, &r.RoleKey, &r.EstimatedHours, &r.StoryPoints, &r.LoggedHours); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.ByMember = append(out.ByMember, r)
+		out.LoggedHours += r.LoggedHours
+
+		role, ok := byRole[r.RoleKey]
+		if !ok {
+			role = &TimeReportRow{RoleKey: r.RoleKey}
+			byRole[r.RoleKey] = role
+		}
+		role.EstimatedHours += r.EstimatedHours
+		role.StoryPoints += r.StoryPoints
+		role.LoggedHours += r.LoggedHours
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	for _, r := range byRole {
+		out.ByRole = append(out.ByRole, *r)
+	}
+	sort.Slice(out.ByRole, func(i, j int) bool { return out.ByRole[i].RoleKey < out.ByRole[j].RoleKey })
+
+	c.JSON(http.StatusOK, out)
+}
+
+// validEstimate responds with a 400 and returns false for negative
+// estimates. Zero is allowed and means "no estimate".
+func validEstimate(c *gin.Context, hours *float64, points *int) bool {
+	if hours != nil && (*hours < 0 || *hours > 10000) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid estimate_hours"})
+		return false
+	}
+	if points != nil && (*points < 0 || *points > 1000) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid story_points"})
+		return false
+	}
+	return true
+}
+
+// nonZeroEstimate maps zero estimates to nil so they are stored as NULL.
+func nonZeroEstimate(hours *float64, points *int) (*float64, *int) {
+	if hours != nil && *hours == 0 {
+		hours = nil
+	}
+	if points != nil && *points == 0 {
+		points = nil
+	}
+	return hours, points
+}
+
+// timeEntryColumns lists the columns scanTimeEntry expects. Queries must
+// alias time_entries as te and join the user as u.
+const timeEntryColumns = `
+	te.id::text,
+	te.task_id::text,
+	te.user_id::text,
+	u.username,
+	te.started_at,
+	te.ended_at,
+	` + entryMinutes + `,
+	te.note`
+
+func loadTimeEntry(ctx context.Context, q querier, entryID string) (TimeEntry, error) {
+	return scanTimeEntry(q.QueryRow(ctx, `
+		select `+timeEntryColumns+`
+		from time_entries te
+		join users u on u.id = te.user_id
+		where te.id::text = $1
+	`, entryID))
+}
+
+// stopProjectTimers ends running timers on the project's tasks, only
+// userID's unless it is empty. Used when a member leaves and when the
+// project is trashed, so nobody keeps a timer they can no longer reach.
+func stopProjectTimers(ctx context.Context, tx pgx.Tx, projectID, userID string) error {
+	_, err := tx.Exec(ctx, `
+		update time_entries te
+		set ended_at = now()
+		from tasks t
+		where t.id = te.task_id
+			and t.project_id::text = $1
+			and ($2 = '' or te.user_id::text = $2)
+			and te.ended_at is null
+	`, projectID, userID)
+	return err
+}
+
+// loadRunningTimer returns nil when the user has no running timer.
+func loadRunningTimer(ctx context.Context, q querier, userID string) (*TimeEntry, error) {
+	e, err := scanTimeEntry(q.QueryRow(ctx, `
+		select `+timeEntryColumns+`
+		from time_entries te
+		join users u on u.id = te.user_id
+		where te.user_id::text = $1 and te.ended_at is null
+	`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+func scanTimeEntry(row pgx.Row) (TimeEntry, error) {
+	var e TimeEntry
+	var startedAt time.Time
+	var endedAt *time.Time
+	if err := row.Scan(
+		&e.ID,
+		&e.TaskID,
+		&e.UserID,
+		&e.Username,
+		&startedAt,
+		&endedAt,
+		&e.Minutes,
+		&e.Note,
+	); err != nil {
+		return TimeEntry{}, err
+	}
+
+	e.StartedAt = startedAt.UTC().Format(time.RFC3339)
+	if endedAt != nil {
+		s := endedAt.UTC().Format(time.RFC3339)
+		e.EndedAt = &s
+	}
+	e.IsRunning = endedAt == nil
+	return e, nil
+}
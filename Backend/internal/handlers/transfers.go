@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ========= Transfer DTOs (responses) =========
+type Transfer struct {
+	ID           string `json:"id"`
+	ProjectID    string `json:"project_id"`
+	ProjectName  string `json:"project_name"`
+	FromUserID   string `json:"from_user_id"`
+	FromUsername string `json:"from_username"`
+	ToUserID     string `json:"to_user_id"`
+	ToUsername   string `json:"to_username"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// ========= Requests =========
+type createTransferReq struct {
+	UserID string `json:"user_id"`
+}
+
+// CreateTransfer offers ownership of a project to another member. Ownership
+// only moves once the recipient accepts; a new offer replaces any pending one.
+func (h *Handler) CreateTransfer(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id"})
+		return
+	}
+
+	var req createTransferReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	toID := strings.ToLower(strings.TrimSpace(req.UserID))
+	if toID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user_id"})
+		return
+	}
+	if toID == myID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot transfer to yourself"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	ownerID, err := projectOwnerID(ctx, tx, projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if ownerID != myID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "member not owner of the project"})
+		return
+	}
+
+	isMember, err := isProjectMember(ctx, tx, projectID, toID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipient is not a project member"})
+		return
+	}
+
+	var out Transfer
+	var createdAt time.Time
+	if err := tx.QueryRow(ctx, `
+		with t as (
+			insert into project_transfers (project_id, from_user_id, to_user_id)
+			values ($1::uuid, $2::uuid, $3::uuid)
+			on conflict (project_id) do update
+			set from_user_id = excluded.from_user_id,
+				to_user_id = excluded.to_user_id,
+				created_at = now()
+			returning *
+		)
+		select
+			t.id::text,
+			t.project_id::text,
+			p.name,
+			t.from_user_id::text,
+			fu.username,
+			t.to_user_id::text,
+			tu.username,
+			t.created_at
+		from t
+		join projects p on p.id = t.project_id
+		join users fu on fu.id = t.from_user_id
+		join users tu on tu.id = t.to_user_id
+	`, projectID, myID, toID).Scan(
+		&out.ID,
+		&out.ProjectID,
+		&out.ProjectName,
+		&out.FromUserID,
+		&out.FromUsername,
+		&out.ToUserID,
+		&out.ToUsername,
+		&createdAt,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) CancelTransfer(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID := strings.ToLower(strings.TrimSpace(c.Param("projectId")))
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing project id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from project_transfers
+		where project_id::text = $1
+			and from_user_id::text = $2
+	`, projectID, myID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) ListMyTransfers(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select
+			t.id::text,
+			t.project_id::text,
+			p.name,
+			t.from_user_id::text,
+			fu.username,
+			t.to_user_id::text,
+			tu.username,
+			t.created_at
+		from project_transfers t
+		join projects p on p.id = t.project_id
+		join users fu on fu.id = t.from_user_id
+		join users tu on tu.id = t.to_user_id
+		where t.to_user_id::text = $1
+		order by t.created_at desc
+	`, myID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []Transfer{}
+	for rows.Next() {
+		var t Transfer
+		var createdAt time.Time
+		if err := rows.Scan(
+			&t.ID,
+			&t.ProjectID,
+			&t.ProjectName,
+			&t.FromUserID,
+			&t.FromUsername,
+			&t.ToUserID,
+			&t.ToUsername,
+			&createdAt,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		t.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) AcceptTransfer(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	transferID := strings.ToLower(strings.TrimSpace(c.Param("transferId")))
+	if transferID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing transfer id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	tx, err := h.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var projectID, fromID string
+	if err := tx.QueryRow(ctx, `
+		select project_id::text, from_user_id::text
+		from project_transfers
+		where id::text = $1 and to_user_id::text = $2
+		for update
+	`, transferID, myID).Scan(&projectID, &fromID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	// The offer is only valid while the sender still owns the project and the
+	// recipient is still a member of it.
+	isMember, err := isProjectMember(ctx, tx, projectID, myID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		update projects
+		set owner_id = $1::uuid
+		where id::text = $2
+			and owner_id::text = $3
+			and $4::boolean
+	`, myID, projectID, fromID, isMember)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from project_transfers where id::text = $1
+	`, transferID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "transfer no longer valid"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "project_id": projectID, "owner_id": myID})
+}
+
+func (h *Handler) DeclineTransfer(c *gin.Context) {
+	myID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	transferID := strings.ToLower(strings.TrimSpace(c.Param("transferId")))
+	if transferID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing transfer id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx, `
+		delete from project_transfers
+		where id::text = $1
+			and to_user_id::text = $2
+	`, transferID, myID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashRetention is how long a deleted project can be restored before the
+// purge job removes it permanently.
+const TrashRetention = 30 * 24 * time.Hour
+
+// ========= Trash DTOs (responses) =========
+type TrashedProject struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	DeletedAt   string `json:"deleted_at"`
+	PurgeAt     string `json:"purge_at"`
+}
+
+func (h *Handler) ArchiveProject(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+func (h *Handler) UnarchiveProject(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+func (h *Handler) setArchived(c *gin.Context, archived bool) {
+	ownerID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("projectId"))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx,
+		`update projects
+		set archived_at = case when $1::boolean then coalesce(archived_at, now()) else null end
+		where id = $2::uuid and `+managedBy("projects", "$3")+` and deleted_at is null
+	`, archived, id, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "is_archived": archived})
+}
+
+func (h *Handler) ListTrash(c *gin.Context) {
+	ownerID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.Query(ctx, `
+		select id::text, name, description, deleted_at
+		from projects
+		where `+managedBy("projects", "$1")+`
+			and deleted_at is not null
+			and deleted_at > now() - $2::interval
+		order by deleted_at desc
+	`, ownerID, TrashRetention)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []TrashedProject{}
+	for rows.Next() {
+		var p TrashedProject
+		var deletedAt time.Time
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &deletedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		p.DeletedAt = deletedAt.UTC().Format(time.RFC3339)
+		p.PurgeAt = deletedAt.Add(TrashRetention).UTC().Format(time.RFC3339)
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) RestoreProject(c *gin.Context) {
+	ownerID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("projectId"))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx,
+		`update projects
+		set deleted_at = null
+		where id = $1::uuid
+			and `+managedBy("projects", "$2")+`
+			and deleted_at is not null
+			and deleted_at > now() - $3::interval
+	`, id, ownerID, TrashRetention)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// PurgeProject permanently deletes a project that is already in the trash,
+// without waiting for the purge job.
+func (h *Handler) PurgeProject(c *gin.Context) {
+	ownerID, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("projectId"))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	cmd, err := h.DB.Exec(ctx,
+		`delete from projects
+		where id = $1::uuid and `+managedBy("projects", "$2")+` and deleted_at is not null
+	`, id, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -0,0 +1,525 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultViewSort = "board"
+
+// ========= Saved View DTOs (responses) =========
+type SavedView struct {
+	ID        string     `json:"id"`
+	OwnerID   string     `json:"owner_id"`
+	ProjectID *string    `json:"project_id"` // nil spans all of the viewer's projects
+	Shared    bool       `json:"shared"`     // visible to every project member
+	Name      string     `json:"name"`
+	Filter    TaskFilter `json:"filter"`
+	Sort      string     `json:"sort"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+// ========= Requests =========
+type createViewReq struct {
+	Name      string     `json:"name"`
+	ProjectID *string    `json:"project_id"`
+	Shared    bool       `json:"shared"`
+	Filter    TaskFilter `json:"filter"`
+	Sort      string     `json:"sort"`
+}
+
+type updateViewReq struct {
+	Name   *string     `json:"name"`
+	Shared *bool       `json:"shared"`
+	Filter *TaskFilter `json:"filter"`
+	Sort   *string     `json:"sort"`
+}
+
+type viewTaskCursor struct {
+	Offset int `json:"o"`
+}
+
+// ListViews returns the caller's own views plus views shared in their
+// projects. ?project_id= narrows it to one project's views.
+func (h *Handler) ListViews(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	var projectID *string
+	if raw := strings.TrimSpace(c.Query("project_id")); raw != "" {
+		id, err := uuid.Parse(strings.ToLower(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+		s := id.String()
+		projectID = &s
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if projectID != nil {
+		allowed, err := isProjectMember(ctx, h.DB, *projectID, uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+			return
+		}
+	}
+
+	rows, err := h.DB.Query(ctx, `
+		select `+viewColumns+`
+		from saved_views v
+		where (`+viewVisible+`)
+			and ($2::text is null or v.project_id::text = $2)
+		order by v.project_id nulls first, lower(v.name), v.id
+	`, uid, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := []SavedView{}
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) CreateView(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	var req createViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+		return
+	}
+	if err := req.Filter.normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sort := strings.TrimSpace(req.Sort)
+	if sort == "" {
+		sort = defaultViewSort
+	}
+	if _, ok := taskSorts[sort]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return
+	}
+
+	var projectID *string
+	if req.ProjectID != nil {
+		id, err := uuid.Parse(strings.ToLower(strings.TrimSpace(*req.ProjectID)))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+		s := id.String()
+		projectID = &s
+	}
+	if req.Shared && projectID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shared views need a project"})
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if projectID != nil {
+		allowed, err := isProjectMember(ctx, h.DB, *projectID, uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a project member"})
+			return
+		}
+	}
+
+	var id string
+	if err := h.DB.QueryRow(ctx, `
+		insert into saved_views (owner_id, project_id, shared, name, filter, sort)
+		values ($1::uuid, $2::uuid, $3, $4, $5, $6)
+		returning id::text
+	`, uid, projectID, req.Shared, name, req.Filter, sort).Scan(&id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadView(ctx, h.DB, id, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) GetView(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	viewID, ok := parseViewPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	out, err := loadView(ctx, h.DB, viewID, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// UpdateView is limited to the view's owner, and for shared views also the
+// project owner, who can tidy up what the team sees.
+func (h *Handler) UpdateView(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	viewID, ok := parseViewPath(c)
+	if !ok {
+		return
+	}
+
+	var req updateViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad json"})
+		return
+	}
+
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing name"})
+			return
+		}
+		req.Name = &n
+	}
+	if req.Filter != nil {
+		if err := req.Filter.normalize(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Sort != nil {
+		s := strings.TrimSpace(*req.Sort)
+		if _, ok := taskSorts[s]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+			return
+		}
+		req.Sort = &s
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	current, ok := h.requireViewEditor(c, ctx, viewID, uid)
+	if !ok {
+		return
+	}
+	if req.Shared != nil && *req.Shared && current.ProjectID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shared views need a project"})
+		return
+	}
+
+	if _, err := h.DB.Exec(ctx, `
+		update saved_views
+		set name = coalesce($2, name),
+			shared = coalesce($3, shared),
+			filter = coalesce($4, filter),
+			sort = coalesce($5, sort),
+			updated_at = now()
+		where id::text = $1
+	`, viewID, req.Name, req.Shared, req.Filter, req.Sort); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	out, err := loadView(ctx, h.DB, viewID, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// a project owner unsharing someone else's view can no longer see it
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *Handler) DeleteView(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	viewID, ok := parseViewPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	if _, ok := h.requireViewEditor(c, ctx, viewID, uid); !ok {
+		return
+	}
+
+	if _, err := h.DB.Exec(ctx, `delete from saved_views where id::text = $1`, viewID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// ListViewTasks evaluates a view for the caller: "me" in its filter means
+// the caller, and a view without a project spans every project they belong
+// to. Pages use an offset cursor because the sort may not be unique on any
+// seekable key.
+func (h *Handler) ListViewTasks(c *gin.Context) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	viewID, ok := parseViewPath(c)
+	if !ok {
+		return
+	}
+
+	limit, ok := pageLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	var after viewTaskCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		if err := decodeCursor(raw, &after); err != nil || after.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	ctx, cancel := contextTimeout(c, 8*time.Second)
+	defer cancel()
+
+	view, err := loadView(ctx, h.DB, viewID, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	// Only projects the caller currently belongs to, whatever the view says
+	where, args := view.Filter.where(uid, []any{uid})
+	scope := `t.project_id in (
+			select pm.project_id
+			from projects_members pm
+			join projects p on p.id = pm.project_id
+			where pm.user_id::text = $1 and p.deleted_at is null
+		)`
+	where = append([]string{scope}, where...)
+	if view.ProjectID != nil {
+		args = append(args, *view.ProjectID)
+		where = append(where, fmt.Sprintf("t.project_id::text = $%d", len(args)))
+	}
+
+	orderBy, ok := taskSorts[view.Sort]
+	if !ok {
+		orderBy = taskSorts[defaultViewSort]
+	}
+
+	args = append(args, after.Offset, limit+1)
+	q := fmt.Sprintf(`
+		select %s
+		from tasks t
+		where %s
+		order by %s
+		offset $%d
+		limit $%d
+	`, taskColumns, strings.Join(where, " and "), orderBy, len(args)-1, len(args))
+
+	rows, err := h.DB.Query(ctx, q, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+	defer rows.Close()
+
+	out := TaskPage{Tasks: []Task{}}
+	for rows.Next() {
+		if len(out.Tasks) == limit {
+			out.NextCursor = encodeCursor(viewTaskCursor{Offset: after.Offset + limit})
+			break
+		}
+
+		t, err := scanTask(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return
+		}
+		out.Tasks = append(out.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// requireViewEditor loads a view the caller may change, writing 404 or 403
+// otherwise.
+func (h *Handler) requireViewEditor(c *gin.Context, ctx context.Context, viewID, uid string) (SavedView, bool) {
+	view, err := loadView(ctx, h.DB, viewID, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "view not found"})
+			return SavedView{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return SavedView{}, false
+	}
+	if view.OwnerID == uid {
+		return view, true
+	}
+
+	if view.Shared && view.ProjectID != nil {
+		manages, err := managesProject(ctx, h.DB, *view.ProjectID, uid)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+			return SavedView{}, false
+		}
+		if manages {
+			return view, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "not your view"})
+	return SavedView{}, false
+}
+
+func parseViewPath(c *gin.Context) (string, bool) {
+	id, err := uuid.Parse(strings.ToLower(strings.TrimSpace(c.Param("viewId"))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid view id"})
+		return "", false
+	}
+	return id.String(), true
+}
+
+// viewVisible is true for views aliased v that the user in $1 may see: their
+// own, and shared ones in projects they belong to.
+const viewVisible = `v.owner_id::text = $1
+	or (v.shared and exists (
+		select 1
+		from projects_members pm
+		join projects p on p.id = pm.project_id
+		where pm.project_id = v.project_id
+			and pm.user_id::text = $1
+			and p.deleted_at is null
+	))`
+
+const viewColumns = `
+	v.id::text,
+	v.owner_id::text,
+	coalesce(v.project_id::text, ''),
+	v.shared,
+	v.name,
+	v.filter,
+	v.sort,
+	v.created_at,
+	v.updated_at
+`
+
+// loadView returns pgx.ErrNoRows when the view doesn't exist or uid can't
+// see it.
+func loadView(ctx context.Context, q querier, viewID, uid string) (SavedView, error) {
+	return scanView(q.QueryRow(ctx, `
+		select `+viewColumns+`
+		from saved_views v
+		where v.id::text = $2 and (`+viewVisible+`)
+	`, uid, viewID))
+}
+
+func scanView(row pgx.Row) (SavedView, error) {
+	var v SavedView
+	var projectID string
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(
+		&v.ID,
+		&v.OwnerID,
+		&projectID,
+		&v.Shared,
+		&v.Name,
+		&v.Filter,
+		&v.Sort,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return SavedView{}, err
+	}
+	if projectID != "" {
+		v.ProjectID = &projectID
+	}
+	v.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	v.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return v, nil
+}
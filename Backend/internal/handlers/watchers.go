@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// TaskUser is an assignee or watcher as shown on a task.
+type TaskUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// WatchTask subscribes the caller to notifications about a task.
+func (h *Handler) WatchTask(c *gin.Context) {
+	h.setWatching(c, true)
+}
+
+func (h *Handler) UnwatchTask(c *gin.Context) {
+	h.setWatching(c, false)
+}
+
+func (h *Handler) setWatching(c *gin.Context, watch bool) {
+	uid, ok := getAuthUID(c)
+	if !ok {
+		return
+	}
+
+	projectID, taskID, ok := parseTaskPath(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := contextTimeout(c, 5*time.Second)
+	defer cancel()
+
+	// Watching doesn't edit the task, so archived projects can be followed
+	if !requireTaskAccess(c, ctx, h.DB, projectID, taskID, uid, false) {
+		return
+	}
+
+	q := `delete from task_watchers where task_id::text = $1 and user_id::text = $2`
+	if watch {
+		q = `
+			insert into task_watchers (task_id, user_id)
+			values ($1::uuid, $2::uuid)
+			on conflict do nothing
+		`
+	}
+	if _, err := h.DB.Exec(ctx, q, taskID, uid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "watching": watch})
+}
+
+// normalizeUserIDs lowercases and dedupes user ids; false means one of them
+// is not a UUID.
+func normalizeUserIDs(raw []string) ([]string, bool) {
+	seen := make(map[string]bool, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, id := range raw {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, true
+}
+
+// requireAssignableMembers is requireAssignableMember for a set of users.
+func requireAssignableMembers(c *gin.Context, ctx context.Context, q querier, projectID string, userIDs []string) bool {
+	if len(userIDs) == 0 {
+		return true
+	}
+
+	var members int
+	if err := q.QueryRow(ctx, `
+		select count(*)
+		from projects_members
+		where project_id::text = $1 and user_id::text = any($2)
+	`, projectID, userIDs).Scan(&members); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
+		return false
+	}
+	if members != len(userIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "assignee is not a project member"})
+		return false
+	}
+	return true
+}
+
+// setTaskAssignees replaces a task's assignees and returns the users that
+// were not assigned before.
+func setTaskAssignees(ctx context.Context, tx pgx.Tx, taskID string, userIDs []string) ([]string, error) {
+	if _, err := tx.Exec(ctx, `
+		delete from task_assignees
+		where task_id::text = $1 and user_id::text <> all($2)
+	`, taskID, userIDs); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		insert into task_assignees (task_id, user_id)
+		select $1::uuid, u::uuid from unnest($2::text[]) as u
+		on conflict do nothing
+		returning user_id::text
+	`, taskID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"forge-api/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteOrphanedFiles removes stored files whose attachment rows are gone.
+// Deleting an attachment (directly, or with its task or project) queues its
+// key in storage_deletions; this drains that queue every interval until ctx
+// is done.
+func DeleteOrphanedFiles(ctx context.Context, pool *pgxpool.Pool, store storage.Storage, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := deleteOrphansOnce(ctx, pool, store); err != nil {
+			log.Printf("delete orphaned files: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deleteOrphansOnce(ctx context.Context, pool *pgxpool.Pool, store storage.Storage) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	rows, err := pool.Query(ctx, `
+		select storage_key from storage_deletions
+		order by queued_at
+		limit 500
+	`)
+	if err != nil {
+		return err
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	deleted := 0
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			// leave it queued for the next run
+			log.Printf("delete orphaned file %s: %v", key, err)
+			continue
+		}
+		if _, err := pool.Exec(ctx, `delete from storage_deletions where storage_key = $1`, key); err != nil {
+			return err
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		log.Printf("deleted %d orphaned file(s)", deleted)
+	}
+	return nil
+}
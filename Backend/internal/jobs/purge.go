@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PurgeDeletedProjects permanently removes projects that have been in the
+// trash for longer than retention, checking every interval until ctx is done.
+func PurgeDeletedProjects(ctx context.Context, pool *pgxpool.Pool, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := purgeOnce(ctx, pool, retention); err != nil {
+			log.Printf("purge deleted projects: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeOnce(ctx context.Context, pool *pgxpool.Pool, retention time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd, err := pool.Exec(ctx, `
+		delete from projects
+		where deleted_at is not null
+			and deleted_at <= now() - $1::interval
+	`, retention)
+	if err != nil {
+		return err
+	}
+
+	if n := cmd.RowsAffected(); n > 0 {
+		log.Printf("purged %d deleted project(s)", n)
+	}
+
+	// task history has no foreign keys (it outlives deleted tasks), so it is
+	// cleared here once its project is gone
+	if _, err := pool.Exec(ctx, `
+		delete from task_history h
+		where not exists (select 1 from projects p where p.id = h.project_id)
+	`); err != nil {
+		return err
+	}
+
+	// staged imports only need to last until they are confirmed
+	if _, err := pool.Exec(ctx, `
+		delete from task_imports
+		where created_at <= now() - interval '1 day'
+	`); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"forge-api/internal/rank"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxRankLen is the key length past which a list is respread. Repeatedly
+// inserting into the same gap grows keys by about one digit per insert.
+const maxRankLen = 16
+
+// RebalanceRanks respreads the rank keys of board columns, project lists and
+// task checklists whose keys have grown long or collided, checking every interval until ctx
+// is done.
+func RebalanceRanks(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := rebalanceOnce(ctx, pool); err != nil {
+			log.Printf("rebalance ranks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func rebalanceOnce(ctx context.Context, pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	columns, err := collectGroups(ctx, pool, `
+		select project_id::text, status
+		from tasks
+		group by project_id, status
+		having max(length(rank)) > $1 or count(distinct rank) < count(*)
+	`)
+	if err != nil {
+		return err
+	}
+	for _, g := range columns {
+		if err := respread(ctx, pool, `
+			select id::text
+			from tasks
+			where project_id::text = $1 and status = $2
+			order by rank, id
+			for update
+		`, `update tasks set rank = $2 where id::text = $1`, g...); err != nil {
+			return err
+		}
+	}
+
+	lists, err := collectGroups(ctx, pool, `
+		select user_id::text
+		from projects_members
+		group by user_id
+		having max(length(rank)) > $1 or count(distinct rank) < count(*)
+	`)
+	if err != nil {
+		return err
+	}
+	for _, g := range lists {
+		if err := respread(ctx, pool, `
+			select id::text
+			from projects_members
+			where user_id::text = $1
+			order by rank, id
+			for update
+		`, `update projects_members set rank = $2 where id::text = $1`, g...); err != nil {
+			return err
+		}
+	}
+
+	checklists, err := collectGroups(ctx, pool, `
+		select task_id::text
+		from task_checklist_items
+		group by task_id
+		having max(length(rank)) > $1 or count(distinct rank) < count(*)
+	`)
+	if err != nil {
+		return err
+	}
+	for _, g := range checklists {
+		if err := respread(ctx, pool, `
+			select id::text
+			from task_checklist_items
+			where task_id::text = $1
+			order by rank, id
+			for update
+		`, `update task_checklist_items set rank = $2 where id::text = $1`, g...); err != nil {
+			return err
+		}
+	}
+
+	if n := len(columns) + len(lists) + len(checklists); n > 0 {
+		log.Printf("rebalanced %d rank list(s)", n)
+	}
+	return nil
+}
+
+// collectGroups returns the key columns of every list that needs respreading.
+func collectGroups(ctx context.Context, pool *pgxpool.Pool, sql string) ([][]any, error) {
+	rows, err := pool.Query(ctx, sql, maxRankLen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out [][]any
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vals)
+	}
+	return out, rows.Err()
+}
+
+// respread locks one list and gives it evenly spaced keys in its current
+// order, so concurrent moves either finish first or wait for the new keys.
+func respread(ctx context.Context, pool *pgxpool.Pool, selectSQL, updateSQL string, args ...any) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, selectSQL, args...)
+	if err != nil {
+		return err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for i, k := range rank.Spread(len(ids)) {
+		batch.Queue(updateSQL, ids[i], k)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,115 @@
+// Package rank generates lexicographic ordering keys. A key sorts between any
+// two others without renumbering siblings, so moving an item touches one row.
+//
+// Keys use the base-62 digits 0-9A-Za-z, compare correctly under byte
+// ordering (Postgres collate "C") and never end in '0'.
+package rank
+
+import "strings"
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Between returns a key strictly between a and b. An empty a means "before
+// everything", an empty b means "after everything".
+//
+// If a >= b (two concurrent moves picked the same neighbours) the result sorts
+// just after a; the rebalance job later restores a clean order.
+func Between(a, b string) string {
+	if b != "" && a >= b {
+		return midpoint(a, "")
+	}
+	return midpoint(a, b)
+}
+
+// midpoint follows the fractional-indexing approach: treat keys as base-62
+// fractions and pick the shortest digit string between them.
+func midpoint(a, b string) string {
+	if b != "" {
+		// strip the common prefix, treating a missing digit in a as '0'
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := len(digits)
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+
+	if db-da > 1 {
+		return string(digits[(da+db+1)/2])
+	}
+
+	// first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[da]) + midpoint(tail(a, 1), "")
+}
+
+// Spread returns n evenly spaced, strictly increasing keys, used when
+// rebalancing a list whose keys have grown long or collided.
+func Spread(n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	// enough width that neighbours are at least ~62 apart
+	width := 1
+	for space := len(digits); space < (n+1)*len(digits); space *= len(digits) {
+		width++
+	}
+	total := 1
+	for i := 0; i < width; i++ {
+		total *= len(digits)
+	}
+
+	out := make([]string, n)
+	for i := range out {
+		out[i] = encode((i+1)*total/(n+1), width)
+	}
+	return out
+}
+
+// Append returns n strictly increasing keys that sort after last, for adding
+// several items to the end of a list in one go. Every key extends last by the
+// same few digits, rather than growing a step per item as chained Between
+// calls would.
+func Append(last string, n int) []string {
+	out := Spread(n)
+	for i := range out {
+		out[i] = last + out[i]
+	}
+	return out
+}
+
+func encode(v, width int) string {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = digits[v%len(digits)]
+		v /= len(digits)
+	}
+	return strings.TrimRight(string(b), "0")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return '0'
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
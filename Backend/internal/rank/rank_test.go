@@ -0,0 +1,183 @@
+package rank
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// checkKey fails unless k uses only base-62 digits and doesn't end in '0'.
+func checkKey(t *testing.T, k string) {
+	t.Helper()
+	if k == "" {
+		t.Fatal("empty key")
+	}
+	for i := 0; i < len(k); i++ {
+		if strings.IndexByte(digits, k[i]) < 0 {
+			t.Fatalf("key %q has invalid digit %q", k, k[i])
+		}
+	}
+	if strings.HasSuffix(k, "0") {
+		t.Fatalf("key %q ends in '0'", k)
+	}
+}
+
+// checkBetween fails unless k sorts strictly between a and b, with empty
+// bounds meaning open ends.
+func checkBetween(t *testing.T, a, b, k string) {
+	t.Helper()
+	checkKey(t, k)
+	if a != "" && k <= a {
+		t.Fatalf("Between(%q, %q) = %q, not after %q", a, b, k, a)
+	}
+	if b != "" && k >= b {
+		t.Fatalf("Between(%q, %q) = %q, not before %q", a, b, k, b)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	for _, tc := range []struct{ a, b string }{
+		{"", ""},
+		{"", "1"},
+		{"", "V"},
+		{"V", ""},
+		{"z", ""},
+		{"zzz", ""},
+		{"A", "B"},   // adjacent digits
+		{"A", "A1"},  // b extends a
+		{"A1", "A2"}, // adjacent after a common prefix
+		{"9", "A"},   // across the digit/letter boundary
+		{"Z", "a"},   // across the upper/lower boundary
+		{"y", "z"},
+		{"V", "V01"},
+		{"0V", "1"},
+		{"AzzzzV", "B"},
+		// keys from the migration: row_number as %08x, then 'V'
+		{"00000001V", "00000002V"},
+		{"00000009V", "0000000aV"},
+		{"0000000fV", "00000010V"},
+		{"", "00000001V"},
+		{"000000ffV", ""},
+	} {
+		checkBetween(t, tc.a, tc.b, Between(tc.a, tc.b))
+	}
+}
+
+func TestBetweenExactKeys(t *testing.T) {
+	for _, tc := range []struct{ a, b, want string }{
+		{"", "", "V"},
+		{"A", "B", "AV"},
+		{"A1", "A2", "A1V"},
+		{"V", "V01", "V00V"},
+	} {
+		if got := Between(tc.a, tc.b); got != tc.want {
+			t.Errorf("Between(%q, %q) = %q, want %q", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestBetweenOutOfOrder(t *testing.T) {
+	// concurrent moves can hand in a >= b; the key then goes just after a
+	for _, tc := range []struct{ a, b string }{
+		{"V", "V"},
+		{"b", "a"},
+		{"00000002V", "00000001V"},
+	} {
+		k := Between(tc.a, tc.b)
+		checkKey(t, k)
+		if k <= tc.a {
+			t.Errorf("Between(%q, %q) = %q, not after %q", tc.a, tc.b, k, tc.a)
+		}
+	}
+}
+
+func TestBetweenRepeatedInserts(t *testing.T) {
+	// keep inserting at random positions and check the list stays ordered
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{Between("", "")}
+	for i := 0; i < 2000; i++ {
+		pos := rng.Intn(len(keys) + 1)
+		var a, b string
+		if pos > 0 {
+			a = keys[pos-1]
+		}
+		if pos < len(keys) {
+			b = keys[pos]
+		}
+		k := Between(a, b)
+		checkBetween(t, a, b, k)
+		keys = append(keys[:pos], append([]string{k}, keys[pos:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Fatal("keys out of order after random inserts")
+	}
+}
+
+func TestBetweenAlwaysAtEdges(t *testing.T) {
+	// moving items to the very top or bottom over and over must not stall
+	first, last := "V", "V"
+	for i := 0; i < 500; i++ {
+		k := Between("", first)
+		checkBetween(t, "", first, k)
+		first = k
+
+		k = Between(last, "")
+		checkBetween(t, last, "", k)
+		last = k
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 61, 62, 63, 1000, 5000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			keys := Spread(n)
+			if len(keys) != n {
+				t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+			}
+			for i, k := range keys {
+				checkKey(t, k)
+				if i > 0 && keys[i-1] >= k {
+					t.Fatalf("Spread(%d): %q >= %q at %d", n, keys[i-1], k, i)
+				}
+			}
+			// room left between neighbours for later moves
+			for i := 1; i < len(keys); i++ {
+				checkBetween(t, keys[i-1], keys[i], Between(keys[i-1], keys[i]))
+			}
+		})
+	}
+	if keys := Spread(-1); len(keys) != 0 {
+		t.Errorf("Spread(-1) = %v, want none", keys)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	for _, last := range []string{"", "V", "z", "zzz", "00000003V", Between("A", "B")} {
+		for _, n := range []int{1, 3, 100} {
+			keys := Append(last, n)
+			if len(keys) != n {
+				t.Fatalf("Append(%q, %d) returned %d keys", last, n, len(keys))
+			}
+			prev := last
+			for _, k := range keys {
+				checkKey(t, k)
+				if k <= prev {
+					t.Fatalf("Append(%q, %d): %q does not sort after %q", last, n, k, prev)
+				}
+				prev = k
+			}
+		}
+	}
+}
+
+func TestAppendStaysShort(t *testing.T) {
+	// every appended key extends last by the same few digits
+	keys := Append("00000007V", 1000)
+	for _, k := range keys {
+		if len(k) > len("00000007V")+3 {
+			t.Fatalf("Append key %q grew too long", k)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalRoundTrip(t *testing.T) {
+	l, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	const key = "projects/p1/notes.txt"
+	body := []byte("hello disk")
+	if err := l.Put(ctx, key, bytes.NewReader(body), int64(len(body)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rc, err := l.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("reading object: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("Get = %q, want %q", got, body)
+	}
+
+	if err := l.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := l.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := l.Delete(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete missing key: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalPutShortBody(t *testing.T) {
+	l, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Put(context.Background(), "short", bytes.NewReader([]byte("abc")), 10, ""); err == nil {
+		t.Fatal("Put with a short body succeeded")
+	}
+	if _, err := l.Get(context.Background(), "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("partial object left behind: err = %v", err)
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	l, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/", "../outside", "a/../../outside", "a/.."} {
+		body := []byte("x")
+		if err := l.Put(ctx, key, bytes.NewReader(body), 1, ""); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error", key)
+		}
+		if _, err := l.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): err = %v, want an invalid key error", key, err)
+		}
+		if err := l.Delete(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete(%q): err = %v, want an invalid key error", key, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "outside")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a file was written outside the root: %v", err)
+	}
+}
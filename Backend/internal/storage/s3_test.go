@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// authRe is the shape of a SigV4 Authorization header for the test bucket.
+var authRe = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKIDTEST/\d{8}/eu-test-1/s3/aws4_request, SignedHeaders=[a-z0-9;-]+, Signature=[0-9a-f]{64}$`)
+
+var amzDateRe = regexp.MustCompile(`^\d{8}T\d{6}Z$`)
+
+// fakeBucket is an in-memory S3 stand-in addressed path-style as /bucket/key.
+// It rejects requests that are not signed.
+type fakeBucket struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !authRe.MatchString(auth) {
+		b.t.Errorf("%s %s: bad Authorization %q", r.Method, r.URL.Path, auth)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !amzDateRe.MatchString(r.Header.Get("X-Amz-Date")) {
+		b.t.Errorf("%s %s: bad x-amz-date %q", r.Method, r.URL.Path, r.Header.Get("X-Amz-Date"))
+	}
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != "UNSIGNED-PAYLOAD" {
+		b.t.Errorf("%s %s: bad x-amz-content-sha256 %q", r.Method, r.URL.Path, got)
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/bucket/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b.objects[key] = data
+		b.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := b.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestS3(t *testing.T) (*S3, *fakeBucket) {
+	t.Helper()
+	bucket := &fakeBucket{t: t, objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(bucket)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3(Config{
+		Endpoint:  srv.URL,
+		Region:    "eu-test-1",
+		Bucket:    "bucket",
+		AccessKey: "AKIDTEST",
+		SecretKey: "secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, bucket
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	s, bucket := newTestS3(t)
+	ctx := context.Background()
+
+	const key = "projects/p1/report 2024.txt"
+	body := []byte("hello bucket")
+	if err := s.Put(ctx, key, bytes.NewReader(body), int64(len(body)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := bucket.types[key]; got != "text/plain" {
+		t.Errorf("stored content type = %q, want text/plain", got)
+	}
+
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("reading object: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("Get = %q, want %q", got, body)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := bucket.objects[key]; ok {
+		t.Error("object still stored after Delete")
+	}
+}
+
+func TestS3GetMissing(t *testing.T) {
+	s, _ := newTestS3(t)
+
+	if _, err := s.Get(context.Background(), "no/such/key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing key: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3DeleteMissing(t *testing.T) {
+	s, _ := newTestS3(t)
+
+	// S3 answers 204 for missing keys, so this is not an error
+	if err := s.Delete(context.Background(), "no/such/key"); err != nil {
+		t.Errorf("Delete missing key: %v", err)
+	}
+}
+
+func TestNewS3RequiresConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Bucket: "b", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "http://localhost:9000", AccessKey: "a", SecretKey: "s"},
+		{Endpoint: "localhost", Bucket: "b", AccessKey: "a", SecretKey: "s"},
+	} {
+		if _, err := NewS3(cfg); err == nil {
+			t.Errorf("NewS3(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package storage stores uploaded files behind a small interface so the
+// backend can run against the local filesystem or an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound is returned by Get and Delete when the key does not exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage is a flat key/value blob store. Keys are slash-separated paths
+// generated by the server, never by clients.
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any object
+	// already there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object for reading; the caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures a Storage implementation.
+type Config struct {
+	Driver string // "local" (default) or "s3"
+
+	// local
+	Dir string
+
+	// s3
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // address the bucket as endpoint/bucket (MinIO and most stand-ins)
+}
+
+// New builds the Storage described by cfg.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+	}
+}
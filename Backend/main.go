@@ -13,6 +13,8 @@ import (
 	"forge-api/internal/auth"
 	"forge-api/internal/db"
 	"forge-api/internal/handlers"
+	"forge-api/internal/jobs"
+	"forge-api/internal/storage"
 )
 
 func main() {
@@ -44,12 +46,36 @@ func main() {
 		log.Fatalf("init sql failed: %v", err)
 	}
 
+	// File storage for attachments
+	store, err := storage.New(storage.Config{
+		Driver:    os.Getenv("STORAGE_DRIVER"),
+		Dir:       os.Getenv("STORAGE_DIR"),
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+	})
+	if err != nil {
+		log.Fatalf("storage setup failed: %v", err)
+	}
+
+	// Background jobs
+	go jobs.PurgeDeletedProjects(context.Background(), pool, handlers.TrashRetention, time.Hour)
+	go jobs.RebalanceRanks(context.Background(), pool, 6*time.Hour)
+	go jobs.DeleteOrphanedFiles(context.Background(), pool, store, 10*time.Minute)
+
 	// Gin setup (this prints the [GIN-debug] startup lines in debug mode)
 	r := gin.New()
 	r.Use(gin.Logger(), gin.Recovery())
 
 	// handlers
-	h := handlers.New(pool, []byte(cfg.JWTSecret))
+	h := handlers.New(pool, []byte(cfg.JWTSecret), store)
+	h.Uploads = handlers.UploadLimits{
+		MaxBytes:     handlers.ParseMaxBytes(os.Getenv("UPLOAD_MAX_BYTES")),
+		AllowedTypes: handlers.ParseAllowedTypes(os.Getenv("UPLOAD_ALLOWED_TYPES")),
+	}
 
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"ok": true})
@@ -61,11 +87,15 @@ func main() {
 	r.GET("/auth/validUsername", h.ValidUsername)
 
 	authed := r.Group("/me")
-	authed.Use(auth.GinRequireAuth([]byte(cfg.JWTSecret)))
+	authed.Use(auth.GinRequireAuth([]byte(cfg.JWTSecret)), h.ResolveTaskKey())
 
 	// Profile APIs
 	authed.GET("/profile", h.GetProfile)
 	authed.PUT("/profile", h.UpdateProfile)
+	authed.GET("/profile/visibility", h.GetProfileVisibility)
+	authed.PUT("/profile/visibility", h.UpdateProfileVisibility)
+	authed.GET("/users/:username/profile", h.GetUserProfile)
+	authed.GET("/users/find", h.FindPeople)
 
 	// Skills APIs
 	authed.POST("/skills", h.AddSkill)
@@ -79,18 +109,132 @@ func main() {
 
 	// Projects
 	authed.GET("/projects", h.GetProjects)
+	authed.GET("/projects/summary", h.ListProjectSummaries)
+	authed.GET("/projects/:projectId", h.GetProject)
 	authed.POST("/projects", h.CreateProject)
 	authed.PUT("/projects", h.EditProjectDetails)
 	authed.DELETE("/projects/:projectId", h.DeleteProject)
+	authed.POST("/projects/:projectId/archive", h.ArchiveProject)
+	authed.POST("/projects/:projectId/unarchive", h.UnarchiveProject)
 	authed.PATCH("/projects/:projectId/:pin", h.PinProject)
 	authed.PATCH("/projects/reorder", h.ReorderProjects)
-	authed.PUT("/projects/:id/customRoles", h.AddCustomRoles)
+	authed.PATCH("/projects/:projectId/preferences", h.UpdateProjectPreferences)
+
+	// Project Trash
+	authed.GET("/projects/trash", h.ListTrash)
+	authed.POST("/projects/:projectId/restore", h.RestoreProject)
+	authed.DELETE("/projects/trash/:projectId", h.PurgeProject)
+
+	// Project Custom Roles
+	authed.GET("/projects/:projectId/customRoles", h.ListCustomRoles)
+	authed.PUT("/projects/:projectId/customRoles", h.AddCustomRoles)
+	authed.PATCH("/projects/:projectId/customRoles/:role", h.RenameCustomRole)
+	authed.DELETE("/projects/:projectId/customRoles/:role", h.DeleteCustomRole)
 
 	// Project Tasks
+	authed.GET("/projects/:projectId/tasks", h.ListTasks)
 	authed.POST("/projects/:projectId/tasks", h.AddTask)
+	authed.POST("/projects/:projectId/tasks/bulk", h.BulkUpdateTasks)
 	authed.PATCH("/projects/:projectId/tasks/:taskId", h.UpdateTask)
 	authed.DELETE("/projects/:projectId/tasks/:taskId", h.DeleteTask)
 
+	// Project Labels
+	authed.GET("/projects/:projectId/labels", h.ListLabels)
+	authed.POST("/projects/:projectId/labels", h.CreateLabel)
+	authed.PATCH("/projects/:projectId/labels/:labelId", h.UpdateLabel)
+	authed.DELETE("/projects/:projectId/labels/:labelId", h.DeleteLabel)
+
+	// Sprints
+	authed.GET("/projects/:projectId/sprints", h.ListSprints)
+	authed.POST("/projects/:projectId/sprints", h.CreateSprint)
+	authed.PATCH("/projects/:projectId/sprints/:sprintId", h.UpdateSprint)
+	authed.DELETE("/projects/:projectId/sprints/:sprintId", h.DeleteSprint)
+	authed.POST("/projects/:projectId/sprints/:sprintId/start", h.StartSprint)
+	authed.POST("/projects/:projectId/sprints/:sprintId/close", h.CloseSprint)
+	authed.GET("/projects/:projectId/sprints/:sprintId/summary", h.GetSprintSummary)
+
+	// Milestones
+	authed.GET("/projects/:projectId/milestones", h.ListMilestones)
+	authed.POST("/projects/:projectId/milestones", h.CreateMilestone)
+	authed.PATCH("/projects/:projectId/milestones/:milestoneId", h.UpdateMilestone)
+	authed.DELETE("/projects/:projectId/milestones/:milestoneId", h.DeleteMilestone)
+
+	// Task Watchers
+	authed.POST("/projects/:projectId/tasks/:taskId/watch", h.WatchTask)
+	authed.DELETE("/projects/:projectId/tasks/:taskId/watch", h.UnwatchTask)
+
+	// Task Checklists
+	authed.GET("/projects/:projectId/tasks/:taskId/checklist", h.ListChecklist)
+	authed.POST("/projects/:projectId/tasks/:taskId/checklist", h.AddChecklistItem)
+	authed.PATCH("/projects/:projectId/tasks/:taskId/checklist/:itemId", h.UpdateChecklistItem)
+	authed.DELETE("/projects/:projectId/tasks/:taskId/checklist/:itemId", h.DeleteChecklistItem)
+
+	// Time Tracking
+	authed.GET("/projects/:projectId/tasks/:taskId/time", h.ListTimeEntries)
+	authed.POST("/projects/:projectId/tasks/:taskId/time", h.AddTimeEntry)
+	authed.DELETE("/projects/:projectId/tasks/:taskId/time/:entryId", h.DeleteTimeEntry)
+	authed.POST("/projects/:projectId/tasks/:taskId/timer/start", h.StartTimer)
+	authed.POST("/projects/:projectId/tasks/:taskId/timer/stop", h.StopTimer)
+	authed.GET("/timer", h.GetMyTimer)
+	authed.GET("/projects/:projectId/reports/time", h.GetTimeReport)
+
+	// Burndown & Velocity
+	authed.GET("/projects/:projectId/reports/burndown", h.GetBurndown)
+	authed.GET("/projects/:projectId/reports/velocity", h.GetVelocity)
+
+	// Export & Import
+	authed.GET("/projects/:projectId/export", h.ExportProject)
+	authed.POST("/projects/import", h.ImportProject)
+	authed.POST("/projects/:projectId/imports", h.PreviewTaskImport)
+	authed.POST("/projects/:projectId/imports/:importId/confirm", h.ConfirmTaskImport)
+	authed.DELETE("/projects/:projectId/imports/:importId", h.DiscardTaskImport)
+
+	// Templates & Cloning
+	authed.POST("/projects/:projectId/template", h.SaveProjectTemplate)
+	authed.POST("/projects/:projectId/clone", h.CloneProject)
+	authed.GET("/templates", h.ListTemplates)
+	authed.GET("/templates/:templateId", h.GetTemplate)
+	authed.DELETE("/templates/:templateId", h.DeleteTemplate)
+	authed.POST("/templates/:templateId/projects", h.CreateProjectFromTemplate)
+
+	// Organizations
+	authed.GET("/orgs", h.ListOrgs)
+	authed.POST("/orgs", h.CreateOrg)
+	authed.GET("/orgs/:orgId", h.GetOrg)
+	authed.PATCH("/orgs/:orgId", h.UpdateOrg)
+	authed.DELETE("/orgs/:orgId", h.DeleteOrg)
+	authed.GET("/orgs/:orgId/members", h.ListOrgMembers)
+	authed.POST("/orgs/:orgId/members", h.AddOrgMember)
+	authed.PATCH("/orgs/:orgId/members/:userId", h.UpdateOrgMember)
+	authed.DELETE("/orgs/:orgId/members/:userId", h.RemoveOrgMember)
+	authed.GET("/orgs/:orgId/projects", h.ListOrgProjects)
+	authed.POST("/orgs/:orgId/projects", h.AddOrgProject)
+	authed.DELETE("/orgs/:orgId/projects/:projectId", h.RemoveOrgProject)
+
+	// Attachments
+	authed.GET("/projects/:projectId/attachments", h.ListProjectAttachments)
+	authed.POST("/projects/:projectId/attachments", h.UploadProjectAttachment)
+	authed.GET("/projects/:projectId/attachments/:attachmentId", h.DownloadAttachment)
+	authed.DELETE("/projects/:projectId/attachments/:attachmentId", h.DeleteAttachment)
+	authed.GET("/projects/:projectId/tasks/:taskId/attachments", h.ListTaskAttachments)
+	authed.POST("/projects/:projectId/tasks/:taskId/attachments", h.UploadTaskAttachment)
+
+	// Notifications
+	authed.GET("/notifications", h.ListNotifications)
+	authed.POST("/notifications/read", h.MarkAllNotificationsRead)
+	authed.POST("/notifications/:notificationId/read", h.MarkNotificationRead)
+
+	// Saved Views
+	authed.GET("/views", h.ListViews)
+	authed.POST("/views", h.CreateView)
+	authed.GET("/views/:viewId", h.GetView)
+	authed.PATCH("/views/:viewId", h.UpdateView)
+	authed.DELETE("/views/:viewId", h.DeleteView)
+	authed.GET("/views/:viewId/tasks", h.ListViewTasks)
+
+	// Full-text Search
+	authed.GET("/search", h.Search)
+
 	// User Search
 	authed.GET("/users/search", h.SearchUsers)
 
@@ -105,6 +249,14 @@ func main() {
 	// Project Members
 	authed.PATCH("/projects/:projectId/members/:memberId", h.UpdateMemberRole)
 	authed.DELETE("/projects/:projectId/members/:memberId", h.DeleteMember)
+	authed.POST("/projects/:projectId/leave", h.LeaveProject)
+
+	// Ownership Transfers
+	authed.POST("/projects/:projectId/transfer", h.CreateTransfer)
+	authed.DELETE("/projects/:projectId/transfer", h.CancelTransfer)
+	authed.GET("/transfers", h.ListMyTransfers)
+	authed.POST("/transfers/:transferId/accept", h.AcceptTransfer)
+	authed.POST("/transfers/:transferId/decline", h.DeclineTransfer)
 
 	addr := fmt.Sprintf(":%s", cfg.Port)
 	fmt.Printf("%s Server running on http://localhost:%s\n", time.Now().Format("2006/01/02 15:04:05"), cfg.Port)